@@ -4,10 +4,13 @@ import (
 	"bytes"
 	"context"
 	"encoding/json"
+	"go-micro/logger"
+	"go-micro/servicehandler"
 	"log"
 
 	"github.com/aws/aws-lambda-go/events"
 	"github.com/aws/aws-lambda-go/lambda"
+	"github.com/aws/aws-lambda-go/lambdacontext"
 )
 
 // Response is of type APIGatewayProxyResponse since we're leveraging the
@@ -23,6 +26,15 @@ type EventSpec interface {
 func Handler(ctx context.Context, event events.APIGatewayProxyRequest) (Response, error) {
 	var buf bytes.Buffer
 
+	// Redirect the standard logger to the logger of the invocation
+	lgr := logger.NewLogger()
+	if lc, ok := lambdacontext.FromContext(ctx); ok {
+		lgr.RequestID = lc.AwsRequestID
+	}
+	lgr.CorrelationID = servicehandler.CorrelationIDFromHeaders(event.Headers)
+	defer logger.RedirectStdLog(lgr, logger.DEBUG)()
+	defer lgr.Flush(false)
+
 	log.Println("CTX:")
 	log.Println(ctx)
 	log.Println(event)
@@ -1,8 +1,17 @@
 module go-micro
 
-go 1.16
+go 1.18
 
 require (
 	github.com/aws/aws-lambda-go v1.23.0
-	golang.org/x/tools v0.1.1 // indirect
+	go.opentelemetry.io/contrib/propagators/aws v1.11.1
+	go.opentelemetry.io/otel v1.11.1
+	go.opentelemetry.io/otel/sdk v1.11.1
+	go.opentelemetry.io/otel/trace v1.11.1
+)
+
+require (
+	github.com/go-logr/logr v1.2.3 // indirect
+	github.com/go-logr/stdr v1.2.2 // indirect
+	golang.org/x/sys v0.0.0-20220919091848-fb04ddd9f9c8 // indirect
 )
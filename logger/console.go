@@ -0,0 +1,121 @@
+package logger
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"strings"
+	"time"
+)
+
+// NO_COLOR_ENV is the environment variable disabling the colors of the ConsoleFormatter when set
+const NO_COLOR_ENV = "NO_COLOR"
+
+// CONSOLE_MODULE_WIDTH is the width of the module column of the console format
+const CONSOLE_MODULE_WIDTH = 40
+
+// ANSI escape codes of the console format colors
+const (
+	colorReset = "\x1b[0m"
+	colorDim   = "\x1b[2m"
+)
+
+// levelColors are the colors of the log levels in the console format
+var levelColors = map[LogLevel]string{
+	TRACE: "\x1b[90m",
+	DEBUG: "\x1b[36m",
+	INFO:  "\x1b[32m",
+	WARN:  "\x1b[33m",
+	ERROR: "\x1b[31m",
+	FATAL: "\x1b[1;31m",
+}
+
+// isTerminal will check if the file is a terminal.
+func isTerminal(f *os.File) bool {
+	fi, err := f.Stat()
+	return err == nil && fi.Mode()&os.ModeCharDevice != 0
+}
+
+// stdoutIsTerminal checks if stdout is a terminal, it can be mocked in testing
+var stdoutIsTerminal = func() bool {
+	return isTerminal(os.Stdout)
+}
+
+// DetectFormat will select the log format of the environment: JSON in lambda, CONSOLE when stdout is
+// a terminal, e.g. running the local server, and TEXT otherwise.
+func DetectFormat() LogFormat {
+	if FunctionName != "" {
+		return JSON_FORMAT
+	}
+	if stdoutIsTerminal() {
+		return CONSOLE_FORMAT
+	}
+	return TEXT_FORMAT
+}
+
+// NewConsoleFormatter will create a formatter for reading logs in a terminal. Each log is a line of
+// aligned columns with the time elapsed since the formatter was created, the level, the module and the
+// text, followed by the request ids, the data as indented JSON with sorted keys, the caller, the error
+// chain and the stack. color selects whether the levels are colorized with ANSI escape codes.
+func NewConsoleFormatter(color bool) Formatter {
+	start := time.Now()
+	return func(lg Log) string {
+		return formatConsole(lg, start, color)
+	}
+}
+
+// paint will wrap s in the color when colors are enabled.
+func paint(s string, color string, enabled bool) string {
+	if !enabled || color == "" {
+		return s
+	}
+	return color + s + colorReset
+}
+
+// formatConsole will format the log in the console format with the elapsed time since start.
+func formatConsole(lg Log, start time.Time, color bool) string {
+	elapsed := strings.Repeat(" ", 10)
+	if !lg.Time.IsZero() {
+		elapsed = fmt.Sprintf("+%8.3fs", lg.Time.Sub(start).Seconds())
+	}
+	entry := fmt.Sprintf("%v %v %v %v",
+		paint(elapsed, colorDim, color),
+		paint(fmt.Sprintf("%-5v", lg.LogLevel.String()), levelColors[lg.LogLevel], color),
+		paint(fmt.Sprintf("%-*v", CONSOLE_MODULE_WIDTH, lg.ModuleName), colorDim, color),
+		lg.Text,
+	)
+
+	ids := []string{}
+	if lg.RequestID != "" {
+		ids = append(ids, "requestId="+lg.RequestID)
+	}
+	if lg.CorrelationID != "" {
+		ids = append(ids, "correlationId="+lg.CorrelationID)
+	}
+	if lg.TraceID != "" {
+		ids = append(ids, "traceId="+lg.TraceID)
+	}
+	if len(ids) > 0 {
+		entry += " " + paint(strings.Join(ids, " "), colorDim, color)
+	}
+
+	indent := strings.Repeat(" ", 11)
+	if len(lg.Data) > 0 {
+		data, err := json.MarshalIndent(lg.Data, indent, "  ")
+		if err != nil {
+			data = []byte(fmt.Sprintf("%v", lg.Data))
+		}
+		entry += "\n" + indent + string(data)
+	}
+	if lg.Caller != "" {
+		entry += "\n" + indent + "caller=" + lg.Caller
+	}
+	if len(lg.Errors) > 0 {
+		errorChain, _ := json.Marshal(lg.Errors)
+		entry += "\n" + indent + "errors=" + string(errorChain)
+	}
+	if lg.Stack != "" {
+		entry += "\n" + paint(strings.TrimRight(lg.Stack, "\n"), colorDim, color)
+	}
+	return entry
+}
@@ -0,0 +1,115 @@
+package logger
+
+import (
+	"os"
+	"strings"
+	"testing"
+	"time"
+)
+
+var consoleStart = time.Date(2021, 5, 1, 10, 30, 0, 0, time.UTC)
+
+var formatConsoleTests = []struct {
+	testName string
+	log      Log
+	color    bool
+	want     string
+}{
+	{"text only", Log{LogLevel: INFO, ModuleName: "main.Handler", Time: consoleStart.Add(1500 * time.Millisecond),
+		Text: "Creating user"}, false,
+		"+   1.500s INFO  main.Handler                             Creating user"},
+	{"without time", Log{LogLevel: WARN, ModuleName: "main.Handler", Text: "No time"}, false,
+		"           WARN  main.Handler                             No time"},
+	{"ids and sorted data", Log{LogLevel: DEBUG, ModuleName: "main.Handler", Time: consoleStart,
+		Text: "Loaded user", RequestID: "request-1", CorrelationID: "correlation-1",
+		Data: map[string]interface{}{"userId": "1234", "roles": []string{"admin"}, "age": 30}}, false,
+		"+   0.000s DEBUG main.Handler                             Loaded user requestId=request-1 correlationId=correlation-1\n" +
+			"           {\n" +
+			"             \"age\": 30,\n" +
+			"             \"roles\": [\n" +
+			"               \"admin\"\n" +
+			"             ],\n" +
+			"             \"userId\": \"1234\"\n" +
+			"           }"},
+	{"error", Log{LogLevel: ERROR, ModuleName: "main.Handler", Time: consoleStart, Text: "Failed",
+		Caller: "main.go:10", Errors: []ErrorInfo{{Type: "*errors.errorString", Message: "not found"}},
+		Stack: "goroutine 1 [running]:\n"}, false,
+		"+   0.000s ERROR main.Handler                             Failed\n" +
+			"           caller=main.go:10\n" +
+			"           errors=[{\"message\":\"not found\",\"type\":\"*errors.errorString\",\"depth\":0}]\n" +
+			"goroutine 1 [running]:"},
+	{"colored", Log{LogLevel: WARN, ModuleName: "main.Handler", Time: consoleStart, Text: "Falling back"}, true,
+		"\x1b[2m+   0.000s\x1b[0m \x1b[33mWARN \x1b[0m \x1b[2mmain.Handler                            \x1b[0m Falling back"},
+}
+
+func TestFormatConsole(t *testing.T) {
+	for _, tt := range formatConsoleTests {
+		t.Run(tt.testName, func(t *testing.T) {
+			if got := formatConsole(tt.log, consoleStart, tt.color); got != tt.want {
+				t.Errorf("console format got\n%q\nwant\n%q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestFormatConsoleUnsupportedData(t *testing.T) {
+	line := formatConsole(Log{LogLevel: INFO, Data: map[string]interface{}{"fn": func() {}}}, consoleStart, false)
+	if !strings.Contains(line, "map[fn:") {
+		t.Errorf("console format with unsupported data got %v", line)
+	}
+}
+
+var detectFormatTests = []struct {
+	testName     string
+	functionName string
+	terminal     bool
+	want         LogFormat
+}{
+	{"lambda", "create_user", false, JSON_FORMAT},
+	{"lambda with terminal", "create_user", true, JSON_FORMAT},
+	{"terminal", "", true, CONSOLE_FORMAT},
+	{"redirected", "", false, TEXT_FORMAT},
+}
+
+func TestDetectFormat(t *testing.T) {
+	functionName, isTerminal := FunctionName, stdoutIsTerminal
+	defer func() { FunctionName, stdoutIsTerminal = functionName, isTerminal }()
+	for _, tt := range detectFormatTests {
+		t.Run(tt.testName, func(t *testing.T) {
+			FunctionName = tt.functionName
+			stdoutIsTerminal = func() bool { return tt.terminal }
+			if got := DetectFormat(); got != tt.want {
+				t.Errorf("detected format got %v, want %v", got, tt.want)
+			}
+			if got := NewLogger().Format; got != tt.want {
+				t.Errorf("new logger format got %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestIsTerminal(t *testing.T) {
+	f, err := os.CreateTemp(t.TempDir(), "log")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+	if isTerminal(f) {
+		t.Errorf("regular file detected as a terminal")
+	}
+}
+
+func TestConsoleFormatDisplay(t *testing.T) {
+	lgr := NewLogger()
+	lgr.Format = CONSOLE_FORMAT
+	lgr.Mode = STREAM_MODE
+	out := captureStdout(func() {
+		lgr.LogObj(INFO, "Creating user", map[string]interface{}{"userId": "1234"}, "", false)
+		lgr.DisplayLogsForward()
+	})
+	lines := strings.Split(strings.TrimRight(out, "\n"), "\n")
+	if len(lines) != 8 || !strings.Contains(lines[0], "logger.TestConsoleFormatDisplay") ||
+		!strings.Contains(lines[0], "Creating user") || strings.TrimSpace(lines[2]) != `"userId": "1234"` {
+		t.Errorf("console display got %q", out)
+	}
+}
@@ -0,0 +1,63 @@
+package logger
+
+import "context"
+
+// contextKey is the key of the logger in a context.Context
+type contextKey struct{}
+
+// With will create a child Logger sharing the log history and sinks of lgr that attaches
+// fields to the data of every log. Fields of the log data take precedence over the fields.
+func (lgr Logger) With(fields map[string]interface{}) Logger {
+	child := lgr
+	child.Fields = make(map[string]interface{}, len(lgr.Fields)+len(fields))
+	for k, v := range lgr.Fields {
+		child.Fields[k] = v
+	}
+	for k, v := range fields {
+		child.Fields[k] = v
+	}
+	return child
+}
+
+// withFields will merge the fields of the logger into the data of a log.
+func (lgr Logger) withFields(data map[string]interface{}) map[string]interface{} {
+	if len(lgr.Fields) == 0 {
+		return data
+	}
+	merged := make(map[string]interface{}, len(lgr.Fields)+len(data))
+	for k, v := range lgr.Fields {
+		merged[k] = v
+	}
+	for k, v := range data {
+		merged[k] = v
+	}
+	return merged
+}
+
+// IntoContext will return a copy of ctx carrying the logger.
+func IntoContext(ctx context.Context, lgr Logger) context.Context {
+	if ctx == nil {
+		ctx = context.Background()
+	}
+	return context.WithValue(ctx, contextKey{}, lgr)
+}
+
+// FromContext will return the logger carried by ctx. Without one, a new logger in STREAM_MODE
+// is returned so the logs are still written.
+func FromContext(ctx context.Context) Logger {
+	if lgr, ok := loggerFromContext(ctx); ok {
+		return lgr
+	}
+	lgr := NewLogger()
+	lgr.Mode = STREAM_MODE
+	return lgr
+}
+
+// loggerFromContext will return the logger carried by ctx and whether there is one.
+func loggerFromContext(ctx context.Context) (Logger, bool) {
+	if ctx == nil {
+		return Logger{}, false
+	}
+	lgr, ok := ctx.Value(contextKey{}).(Logger)
+	return lgr, ok
+}
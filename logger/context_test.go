@@ -0,0 +1,86 @@
+package logger
+
+import (
+	"context"
+	"reflect"
+	"testing"
+)
+
+var loggerWithTests = []struct {
+	testName string
+	fields   []map[string]interface{}
+	data     map[string]interface{}
+	wantData map[string]interface{}
+}{
+	{"no fields", nil, nil, nil},
+	{"fields", []map[string]interface{}{{"userId": "1234"}}, nil, map[string]interface{}{"userId": "1234"}},
+	{
+		"nested children",
+		[]map[string]interface{}{{"userId": "1234"}, {"orderId": "5678"}},
+		nil,
+		map[string]interface{}{"userId": "1234", "orderId": "5678"},
+	},
+	{
+		"child overrides field",
+		[]map[string]interface{}{{"userId": "1234"}, {"userId": "4321"}},
+		nil,
+		map[string]interface{}{"userId": "4321"},
+	},
+	{
+		"log data overrides field",
+		[]map[string]interface{}{{"userId": "1234", "orderId": "5678"}},
+		map[string]interface{}{"userId": "4321"},
+		map[string]interface{}{"userId": "4321", "orderId": "5678"},
+	},
+}
+
+func TestLoggerWith(t *testing.T) {
+	for _, tt := range loggerWithTests {
+		t.Run(tt.testName, func(t *testing.T) {
+			lgr := NewLogger()
+			child := lgr
+			for _, fields := range tt.fields {
+				child = child.With(fields)
+			}
+			if tt.data == nil {
+				child.LogTxt(INFO, "Test child log")
+			} else {
+				child.LogObj(INFO, "Test child log", tt.data, "", false)
+			}
+			logs := lgr.LogHistory.Logs()
+			if len(logs) != 1 {
+				t.Fatalf("child log not recorded in the parent log history")
+			}
+			if !reflect.DeepEqual(logs[0].Data, tt.wantData) {
+				t.Errorf("child log data got %v, want %v", logs[0].Data, tt.wantData)
+			}
+		})
+	}
+}
+
+func TestLoggerWithIsolatesParent(t *testing.T) {
+	parent := NewLogger().With(map[string]interface{}{"userId": "1234"})
+	parent.With(map[string]interface{}{"orderId": "5678"})
+	parent.LogTxt(INFO, "Test parent log")
+	if data := parent.LogHistory.Logs()[0].Data; !reflect.DeepEqual(data, map[string]interface{}{"userId": "1234"}) {
+		t.Errorf("child fields leaked into the parent, got %v", data)
+	}
+}
+
+func TestLoggerContext(t *testing.T) {
+	lgr := NewLogger().With(map[string]interface{}{"userId": "1234"})
+	ctx := IntoContext(context.Background(), lgr)
+	FromContext(ctx).LogTxt(INFO, "Test context log")
+	if logs := lgr.LogHistory.Logs(); len(logs) != 1 || logs[0].Data["userId"] != "1234" {
+		t.Errorf("context logger does not log to the request logger, got %v", logs)
+	}
+
+	var nilCtx context.Context
+	if FromContext(IntoContext(nilCtx, lgr)).LogHistory != lgr.LogHistory {
+		t.Errorf("logger not carried by a context created from nil")
+	}
+	fallback := FromContext(context.Background())
+	if fallback.LogHistory == nil || fallback.Mode != STREAM_MODE {
+		t.Errorf("context without logger should return a streaming logger")
+	}
+}
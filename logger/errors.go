@@ -0,0 +1,75 @@
+package logger
+
+import (
+	"errors"
+	"fmt"
+	"path/filepath"
+)
+
+// MAX_ERROR_CHAIN is the maximum number of errors unwrapped from an error chain
+const MAX_ERROR_CHAIN = 32
+
+// ErrorInfo is an error of an unwrapped error chain. Depth is its distance from the logged error.
+type ErrorInfo struct {
+	Message string `json:"message"`
+	Type    string `json:"type"`
+	Depth   int    `json:"depth"`
+}
+
+// callerLocation will return the file:line of a caller with the file relative to its package directory.
+func callerLocation(file string, line int) string {
+	if file == "" {
+		return ""
+	}
+	return fmt.Sprintf("%v/%v:%d", filepath.Base(filepath.Dir(file)), filepath.Base(file), line)
+}
+
+// unwrapErrorChain will list the errors of the chain of err in depth-first order, following both
+// errors.Unwrap and the Unwrap() []error of joined errors.
+func unwrapErrorChain(err error) []ErrorInfo {
+	chain := []ErrorInfo{}
+	var walk func(err error, depth int)
+	walk = func(err error, depth int) {
+		if err == nil || len(chain) >= MAX_ERROR_CHAIN {
+			return
+		}
+		chain = append(chain, ErrorInfo{
+			Message: err.Error(),
+			Type:    fmt.Sprintf("%T", err),
+			Depth:   depth,
+		})
+		if joined, ok := err.(interface{ Unwrap() []error }); ok {
+			for _, e := range joined.Unwrap() {
+				walk(e, depth+1)
+			}
+			return
+		}
+		walk(errors.Unwrap(err), depth+1)
+	}
+	walk(err, 0)
+	return chain
+}
+
+// LogErr will insert a new log of the error with its unwrapped error chain into the log history.
+func (lgr Logger) LogErr(logLvl LogLevel, txt string, err error) {
+	if !lgr.Enabled(logLvl) {
+		return
+	}
+	lg := lgr.newLog(2, logLvl, txt, nil)
+	lg.Errors = unwrapErrorChain(err)
+	lgr.record(lg)
+}
+
+// LogPanic will insert a new log of a recovered panic payload with the goroutine stack captured at
+// recover time, e.g. by debug.Stack. Error payloads are logged with their unwrapped error chain.
+func (lgr Logger) LogPanic(logLvl LogLevel, txt string, recoverPayload interface{}, stack []byte) {
+	if !lgr.Enabled(logLvl) {
+		return
+	}
+	lg := lgr.newLog(2, logLvl, txt, nil)
+	lg.Stack = string(stack)
+	if err, ok := recoverPayload.(error); ok {
+		lg.Errors = unwrapErrorChain(err)
+	}
+	lgr.record(lg)
+}
@@ -0,0 +1,130 @@
+package logger
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"reflect"
+	"runtime/debug"
+	"strings"
+	"testing"
+)
+
+// testJoinedError joins errors like errors.Join
+type testJoinedError struct {
+	errs []error
+}
+
+func (je testJoinedError) Error() string {
+	msgs := []string{}
+	for _, err := range je.errs {
+		msgs = append(msgs, err.Error())
+	}
+	return strings.Join(msgs, "\n")
+}
+
+func (je testJoinedError) Unwrap() []error {
+	return je.errs
+}
+
+var errTestNotFound = errors.New("not found")
+
+var unwrapErrorChainTests = []struct {
+	testName string
+	err      error
+	want     []ErrorInfo
+}{
+	{"nil error", nil, []ErrorInfo{}},
+	{"single error", errTestNotFound, []ErrorInfo{{"not found", "*errors.errorString", 0}}},
+	{
+		"wrapped errors",
+		fmt.Errorf("create user: %w", fmt.Errorf("get item: %w", errTestNotFound)),
+		[]ErrorInfo{
+			{"create user: get item: not found", "*fmt.wrapError", 0},
+			{"get item: not found", "*fmt.wrapError", 1},
+			{"not found", "*errors.errorString", 2},
+		},
+	},
+	{
+		"joined errors",
+		testJoinedError{[]error{fmt.Errorf("email: %w", errTestNotFound), errors.New("invalid age")}},
+		[]ErrorInfo{
+			{"email: not found\ninvalid age", "logger.testJoinedError", 0},
+			{"email: not found", "*fmt.wrapError", 1},
+			{"not found", "*errors.errorString", 2},
+			{"invalid age", "*errors.errorString", 1},
+		},
+	},
+}
+
+func TestUnwrapErrorChain(t *testing.T) {
+	for _, tt := range unwrapErrorChainTests {
+		t.Run(tt.testName, func(t *testing.T) {
+			if got := unwrapErrorChain(tt.err); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("error chain got %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestLoggerCaller(t *testing.T) {
+	lgr := NewLogger()
+	lgr.LogTxt(INFO, "Test info log")
+	lgr.LogTxt(ERROR, "Test error log")
+	lgr.LogObj(FATAL, "Test fatal log", map[string]interface{}{}, "", false)
+	logs := lgr.LogHistory.Logs()
+	if logs[0].Caller != "" {
+		t.Errorf("caller recorded for info log: %v", logs[0].Caller)
+	}
+	for _, lg := range logs[1:] {
+		if !strings.HasPrefix(lg.Caller, "logger/errors_test.go:") || lg.ModuleName != "logger.TestLoggerCaller" {
+			t.Errorf("log caller got %v %v, want this test", lg.Caller, lg.ModuleName)
+		}
+	}
+}
+
+func TestLogErr(t *testing.T) {
+	lgr := NewLogger()
+	lgr.LogErr(ERROR, "Creating user failed", fmt.Errorf("create user: %w", errTestNotFound))
+	lg := lgr.LogHistory.Logs()[0]
+	if len(lg.Errors) != 2 || lg.Errors[1].Message != "not found" || !strings.HasPrefix(lg.Caller, "logger/errors_test.go:") {
+		t.Errorf("error log got %v", lg)
+	}
+
+	entry := map[string]interface{}{}
+	json.Unmarshal([]byte(formatJSON(lg)), &entry)
+	if errs, ok := entry["errors"].([]interface{}); !ok || len(errs) != 2 || entry["caller"] != lg.Caller {
+		t.Errorf("json format errors got %v", entry)
+	}
+	if line := formatText(lg); !strings.Contains(line, "caller="+lg.Caller) || !strings.Contains(line, `"message":"not found"`) {
+		t.Errorf("text format errors got %v", line)
+	}
+}
+
+// panicWithError will panic with the error to test the stack capture at recover time
+func panicWithError(err error) {
+	panic(err)
+}
+
+func TestLogPanic(t *testing.T) {
+	lgr := NewLogger()
+	func() {
+		defer func() {
+			if err := recover(); err != nil {
+				lgr.LogPanic(FATAL, "Recovered panic", err, debug.Stack())
+			}
+		}()
+		panicWithError(fmt.Errorf("handler: %w", errTestNotFound))
+	}()
+
+	lg := lgr.LogHistory.Logs()[0]
+	if !strings.Contains(lg.Stack, "logger.panicWithError") {
+		t.Errorf("panic stack does not include the panicking function:\n%v", lg.Stack)
+	}
+	if len(lg.Errors) != 2 || lg.Errors[0].Message != "handler: not found" {
+		t.Errorf("panic error chain got %v", lg.Errors)
+	}
+	if lines := strings.Split(formatText(lg), "\n"); len(lines) < 2 || !strings.HasPrefix(lines[1], "goroutine") {
+		t.Errorf("text format stack got %v", lines)
+	}
+}
@@ -0,0 +1,129 @@
+package logger
+
+import "time"
+
+// fieldType is the type of the value of a Field
+type fieldType uint8
+
+const (
+	STRING_FIELD   fieldType = iota
+	INT_FIELD      fieldType = iota
+	BOOL_FIELD     fieldType = iota
+	DURATION_FIELD fieldType = iota
+	ERROR_FIELD    fieldType = iota
+	ANY_FIELD      fieldType = iota
+	LAZY_FIELD     fieldType = iota
+)
+
+// Field is a typed key value of the log data. Strings, integers, booleans and durations are stored
+// without boxing them into an interface, and the value of a lazy field is only evaluated when the log
+// is recorded, so the fields of filtered logs cost no allocations.
+type Field struct {
+	Key       string
+	fieldType fieldType
+	integer   int64
+	str       string
+	value     interface{}
+	lazy      func() interface{}
+}
+
+// ERROR_FIELD_KEY is the key of the fields created by Err
+const ERROR_FIELD_KEY = "error"
+
+// String will create a field of a string value.
+func String(key string, val string) Field {
+	return Field{Key: key, fieldType: STRING_FIELD, str: val}
+}
+
+// Int will create a field of an integer value.
+func Int(key string, val int) Field {
+	return Field{Key: key, fieldType: INT_FIELD, integer: int64(val)}
+}
+
+// Int64 will create a field of a 64-bit integer value.
+func Int64(key string, val int64) Field {
+	return Field{Key: key, fieldType: INT_FIELD, integer: val}
+}
+
+// Bool will create a field of a boolean value.
+func Bool(key string, val bool) Field {
+	f := Field{Key: key, fieldType: BOOL_FIELD}
+	if val {
+		f.integer = 1
+	}
+	return f
+}
+
+// Duration will create a field of a duration, logged in its string form like 1.5s.
+func Duration(key string, val time.Duration) Field {
+	return Field{Key: key, fieldType: DURATION_FIELD, integer: int64(val)}
+}
+
+// Err will create an "error" field of the error message. A nil error is logged as null.
+func Err(err error) Field {
+	return Field{Key: ERROR_FIELD_KEY, fieldType: ERROR_FIELD, value: err}
+}
+
+// Any will create a field of any value, flattened like the data of LogObj.
+func Any(key string, val interface{}) Field {
+	return Field{Key: key, fieldType: ANY_FIELD, value: val}
+}
+
+// Lazy will create a field whose value is computed by fn only when the log is recorded. The value
+// is flattened like the data of LogObj.
+func Lazy(key string, fn func() interface{}) Field {
+	return Field{Key: key, fieldType: LAZY_FIELD, lazy: fn}
+}
+
+// logValue will return the value of the field in the log data.
+func (f Field) logValue() interface{} {
+	switch f.fieldType {
+	case STRING_FIELD:
+		return f.str
+	case INT_FIELD:
+		return f.integer
+	case BOOL_FIELD:
+		return f.integer == 1
+	case DURATION_FIELD:
+		return time.Duration(f.integer).String()
+	case ERROR_FIELD:
+		if err, ok := f.value.(error); ok && err != nil {
+			return err.Error()
+		}
+		return nil
+	case LAZY_FIELD:
+		if f.lazy == nil {
+			return nil
+		}
+		return toLogValue(f.lazy())
+	default:
+		return toLogValue(f.value)
+	}
+}
+
+// fieldsToData will convert the fields to the log data. Later fields override earlier fields of the same key.
+func fieldsToData(fields []Field) map[string]interface{} {
+	if len(fields) == 0 {
+		return nil
+	}
+	data := make(map[string]interface{}, len(fields))
+	for _, f := range fields {
+		data[f.Key] = f.logValue()
+	}
+	return data
+}
+
+// isSampledOut will check if a log of the log level is dropped by the minimum level or the sampler,
+// before the log is built.
+func (lgr Logger) isSampledOut(logLvl LogLevel) bool {
+	return !lgr.Enabled(logLvl) || !lgr.Sampler.Keep(Log{LogLevel: logLvl, CorrelationID: lgr.CorrelationID})
+}
+
+// LogFields will insert a new log with the typed fields as its data into the log history. Nothing is
+// allocated and no lazy field is evaluated when the log is below the minimum level or sampled out.
+func (lgr Logger) LogFields(logLvl LogLevel, txt string, fields ...Field) {
+	if lgr.isSampledOut(logLvl) {
+		return
+	}
+	lgr.record(lgr.newLog(2, logLvl, txt, fieldsToData(fields)))
+}
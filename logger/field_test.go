@@ -0,0 +1,175 @@
+package logger
+
+import (
+	"errors"
+	"reflect"
+	"strconv"
+	"testing"
+	"time"
+)
+
+type fieldUser struct {
+	UserID   string `json:"userId"`
+	Password string `json:"password"`
+}
+
+var fieldValueTests = []struct {
+	testName string
+	field    Field
+	want     interface{}
+}{
+	{"string", String("userId", "1234"), "1234"},
+	{"int", Int("attempt", 2), int64(2)},
+	{"int64", Int64("size", 1<<40), int64(1 << 40)},
+	{"bool", Bool("retried", true), true},
+	{"duration", Duration("elapsed", 1500*time.Millisecond), "1.5s"},
+	{"error", Err(errors.New("user not found")), "user not found"},
+	{"nil error", Err(nil), nil},
+	{"any struct", Any("user", fieldUser{UserID: "1234"}), map[string]interface{}{"userId": "1234", "password": ""}},
+	{"any nil", Any("user", nil), nil},
+	{"lazy", Lazy("count", func() interface{} { return []int{1, 2} }), []interface{}{1, 2}},
+	{"nil lazy", Lazy("count", nil), nil},
+}
+
+func TestFieldValue(t *testing.T) {
+	for _, tt := range fieldValueTests {
+		t.Run(tt.testName, func(t *testing.T) {
+			if got := tt.field.logValue(); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("field value got %#v, want %#v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestLogFields(t *testing.T) {
+	sink := NewMemorySink(TRACE)
+	lgr := NewLogger()
+	lgr.Mode = STREAM_MODE
+	lgr.Sinks = []Sink{sink}
+	lgr.Fields = map[string]interface{}{"service": "users"}
+
+	lgr.LogFields(INFO, "Creating new HTTP Response",
+		Int("statusCode", 200),
+		String("returnBody", `{"password": "secret"}`),
+		Any("user", fieldUser{UserID: "1234", Password: "secret"}),
+	)
+	lgr.LogFields(WARN, "No fields")
+
+	logs := sink.Logs()
+	if len(logs) != 2 {
+		t.Fatalf("logs got %v, want 2", len(logs))
+	}
+	want := map[string]interface{}{
+		"service":    "users",
+		"statusCode": int64(200),
+		"returnBody": `{"password": "[REDACTED]"}`,
+		"user":       map[string]interface{}{"userId": "1234", "password": REDACTED},
+	}
+	if !reflect.DeepEqual(logs[0].Data, want) || logs[0].ModuleName != "logger.TestLogFields" {
+		t.Errorf("fields log got %v %v, want %v", logs[0].ModuleName, logs[0].Data, want)
+	}
+	if !reflect.DeepEqual(logs[1].Data, map[string]interface{}{"service": "users"}) {
+		t.Errorf("log without fields got data %v", logs[1].Data)
+	}
+}
+
+func TestLogFieldsFiltered(t *testing.T) {
+	lgr := NewLogger()
+	lgr.MinLevel = ERROR
+	evaluated := false
+	lazy := Lazy("expensive", func() interface{} {
+		evaluated = true
+		return "value"
+	})
+	lgr.LogFields(INFO, "Filtered", lazy)
+
+	sampled := NewLogger()
+	sampled.Sampler = NewSampler(0)
+	sampled.CorrelationID = "correlation-1"
+	sampled.LogFields(INFO, "Sampled out", lazy)
+
+	if evaluated || lgr.LogHistory.Len() != 0 || sampled.LogHistory.Len() != 0 {
+		t.Errorf("filtered log recorded or its lazy field evaluated")
+	}
+
+	allocs := testing.AllocsPerRun(100, func() {
+		lgr.LogFields(INFO, "Filtered",
+			String("userId", "1234"),
+			Int("statusCode", 200),
+			Duration("elapsed", time.Second),
+			Bool("retried", false),
+			lazy,
+		)
+	})
+	if allocs != 0 {
+		t.Errorf("filtered log allocated %v times, want 0", allocs)
+	}
+}
+
+// benchmarkStatusCode and benchmarkBody are the values of the benchmark logs
+var (
+	benchmarkStatusCode = 200
+	benchmarkBody       = `{"message": "OK"}`
+)
+
+// newBenchmarkLogger will create a logger recording INFO logs, or filtering them below minLvl
+func newBenchmarkLogger(minLvl LogLevel) Logger {
+	lgr := NewLogger()
+	lgr.MinLevel = minLvl
+	lgr.LogHistory = NewLogHistory(1000, 0)
+	return lgr
+}
+
+func BenchmarkLogTxtConcat(b *testing.B) {
+	lgr := newBenchmarkLogger(INFO)
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		lgr.LogTxt(INFO, "Creating new HTTP Response. Status Code <"+strconv.Itoa(benchmarkStatusCode)+
+			">. Return Body: "+benchmarkBody)
+	}
+}
+
+func BenchmarkLogObjMap(b *testing.B) {
+	lgr := newBenchmarkLogger(INFO)
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		lgr.LogObj(INFO, "Creating new HTTP Response",
+			map[string]interface{}{"statusCode": benchmarkStatusCode, "returnBody": benchmarkBody}, "", false)
+	}
+}
+
+func BenchmarkLogFields(b *testing.B) {
+	lgr := newBenchmarkLogger(INFO)
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		lgr.LogFields(INFO, "Creating new HTTP Response",
+			Int("statusCode", benchmarkStatusCode), String("returnBody", benchmarkBody))
+	}
+}
+
+func BenchmarkLogTxtConcatFiltered(b *testing.B) {
+	lgr := newBenchmarkLogger(ERROR)
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		lgr.LogTxt(INFO, "Creating new HTTP Response. Status Code <"+strconv.Itoa(benchmarkStatusCode)+
+			">. Return Body: "+benchmarkBody)
+	}
+}
+
+func BenchmarkLogObjMapFiltered(b *testing.B) {
+	lgr := newBenchmarkLogger(ERROR)
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		lgr.LogObj(INFO, "Creating new HTTP Response",
+			map[string]interface{}{"statusCode": benchmarkStatusCode, "returnBody": benchmarkBody}, "", false)
+	}
+}
+
+func BenchmarkLogFieldsFiltered(b *testing.B) {
+	lgr := newBenchmarkLogger(ERROR)
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		lgr.LogFields(INFO, "Creating new HTTP Response",
+			Int("statusCode", benchmarkStatusCode), String("returnBody", benchmarkBody))
+	}
+}
@@ -0,0 +1,263 @@
+package logger
+
+import (
+	"encoding"
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"strings"
+)
+
+// DEFAULT_DATA_TAG is the struct tag used by LogObj when no data tag is given
+const DEFAULT_DATA_TAG = "json"
+
+// MAX_FLATTEN_DEPTH is the nesting depth after which the log data is no longer flattened
+const MAX_FLATTEN_DEPTH = 32
+
+// Placeholders of the values that are not flattened into the log data
+const (
+	CYCLE_PLACEHOLDER     = "<cycle>"
+	MAX_DEPTH_PLACEHOLDER = "<max depth>"
+)
+
+var (
+	errorType         = reflect.TypeOf((*error)(nil)).Elem()
+	jsonMarshalerType = reflect.TypeOf((*json.Marshaler)(nil)).Elem()
+	textMarshalerType = reflect.TypeOf((*encoding.TextMarshaler)(nil)).Elem()
+)
+
+// flattener converts values to log data, tracking the pointers being visited to detect cycles.
+type flattener struct {
+	tag     string
+	visited map[uintptr]bool
+}
+
+// toLogData will convert any value to the data of a log. Structs and maps become maps, other values
+// are stored under the "value" key. Struct fields are named by the tag, defaulting to DEFAULT_DATA_TAG.
+// A panic while converting the value, e.g. from its Error method, is stored under the "logDataError" key.
+func toLogData(data interface{}, tag string) (dataMap map[string]interface{}) {
+	defer func() {
+		if err := recover(); err != nil {
+			dataMap = map[string]interface{}{"logDataError": fmt.Sprint(err)}
+		}
+	}()
+	if data == nil {
+		return nil
+	}
+	if tag == "" {
+		tag = DEFAULT_DATA_TAG
+	}
+	f := flattener{tag: tag, visited: map[uintptr]bool{}}
+	switch flat := f.flatten(reflect.ValueOf(data), 0).(type) {
+	case map[string]interface{}:
+		return flat
+	case nil:
+		return nil
+	default:
+		return map[string]interface{}{"value": flat}
+	}
+}
+
+// toLogValue will convert a single value of the log data like toLogData without storing it in a map.
+// A panic while converting the value is stored as its message.
+func toLogValue(data interface{}) (value interface{}) {
+	defer func() {
+		if err := recover(); err != nil {
+			value = "logDataError: " + fmt.Sprint(err)
+		}
+	}()
+	if data == nil {
+		return nil
+	}
+	f := flattener{tag: DEFAULT_DATA_TAG, visited: map[uintptr]bool{}}
+	return f.flatten(reflect.ValueOf(data), 0)
+}
+
+// parseTag will split a struct tag value into the field name and its options.
+func parseTag(tagVal string) (string, string) {
+	if i := strings.Index(tagVal, ","); i >= 0 {
+		return tagVal[:i], tagVal[i+1:]
+	}
+	return tagVal, ""
+}
+
+// hasTagOption will check if the comma separated tag options contain the option.
+func hasTagOption(opts string, option string) bool {
+	for _, opt := range strings.Split(opts, ",") {
+		if opt == option {
+			return true
+		}
+	}
+	return false
+}
+
+// isEmptyValue will check if the value is empty in the sense of omitempty.
+func isEmptyValue(v reflect.Value) bool {
+	switch v.Kind() {
+	case reflect.Array, reflect.Map, reflect.Slice, reflect.String:
+		return v.Len() == 0
+	case reflect.Bool:
+		return !v.Bool()
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return v.Int() == 0
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		return v.Uint() == 0
+	case reflect.Float32, reflect.Float64:
+		return v.Float() == 0
+	case reflect.Interface, reflect.Ptr:
+		return v.IsNil()
+	}
+	return false
+}
+
+// enter will mark the pointer of a reference value as visited. It returns false when the pointer
+// is already being visited, i.e. the value is part of a cycle.
+func (f flattener) enter(v reflect.Value) bool {
+	ptr := v.Pointer()
+	if ptr == 0 {
+		return true
+	}
+	if f.visited[ptr] {
+		return false
+	}
+	f.visited[ptr] = true
+	return true
+}
+
+// leave will unmark the pointer of a reference value once it is flattened.
+func (f flattener) leave(v reflect.Value) {
+	delete(f.visited, v.Pointer())
+}
+
+// flatten will convert the value to maps, slices and scalars that can be formatted as JSON.
+func (f flattener) flatten(v reflect.Value, depth int) interface{} {
+	if !v.IsValid() {
+		return nil
+	}
+	if depth > MAX_FLATTEN_DEPTH {
+		return MAX_DEPTH_PLACEHOLDER
+	}
+	// Values of unexported embedded structs can't be converted to interfaces
+	if v.CanInterface() && (v.Kind() != reflect.Interface && v.Kind() != reflect.Ptr || !v.IsNil()) {
+		if v.Type().Implements(errorType) {
+			return v.Interface().(error).Error()
+		}
+		if v.Type().Implements(jsonMarshalerType) || v.Type().Implements(textMarshalerType) {
+			return v.Interface()
+		}
+	}
+
+	switch v.Kind() {
+	case reflect.Interface:
+		if v.IsNil() {
+			return nil
+		}
+		return f.flatten(v.Elem(), depth)
+	case reflect.Ptr:
+		if v.IsNil() {
+			return nil
+		}
+		if !f.enter(v) {
+			return CYCLE_PLACEHOLDER
+		}
+		defer f.leave(v)
+		return f.flatten(v.Elem(), depth+1)
+	case reflect.Struct:
+		ret := map[string]interface{}{}
+		f.flattenStruct(v, depth, ret)
+		return ret
+	case reflect.Map:
+		if v.IsNil() {
+			return nil
+		}
+		if !f.enter(v) {
+			return CYCLE_PLACEHOLDER
+		}
+		defer f.leave(v)
+		ret := make(map[string]interface{}, v.Len())
+		iter := v.MapRange()
+		for iter.Next() {
+			ret[fmt.Sprint(iter.Key())] = f.flatten(iter.Value(), depth+1)
+		}
+		return ret
+	case reflect.Slice:
+		if v.IsNil() {
+			return nil
+		}
+		if v.Type().Elem().Kind() == reflect.Uint8 && v.CanInterface() {
+			return v.Interface()
+		}
+		if !f.enter(v) {
+			return CYCLE_PLACEHOLDER
+		}
+		defer f.leave(v)
+		return f.flattenList(v, depth)
+	case reflect.Array:
+		return f.flattenList(v, depth)
+	case reflect.Chan, reflect.Func, reflect.UnsafePointer:
+		return v.Type().String()
+	}
+	if v.CanInterface() {
+		return v.Interface()
+	}
+	return fmt.Sprint(v)
+}
+
+// flattenList will convert the elements of a slice or an array.
+func (f flattener) flattenList(v reflect.Value, depth int) []interface{} {
+	ret := make([]interface{}, v.Len())
+	for i := range ret {
+		ret[i] = f.flatten(v.Index(i), depth+1)
+	}
+	return ret
+}
+
+// flattenStruct will convert the exported fields of the struct into ret, named by the tag of the flattener.
+// Fields tagged "-" are skipped, fields tagged omitempty are skipped when empty and the fields of untagged
+// embedded structs are promoted like in encoding/json. An embedded pointer already being visited is stored
+// as CYCLE_PLACEHOLDER under the name of its type.
+func (f flattener) flattenStruct(v reflect.Value, depth int, ret map[string]interface{}) {
+	if depth > MAX_FLATTEN_DEPTH {
+		return
+	}
+	for i := 0; i < v.NumField(); i++ {
+		field := v.Type().Field(i)
+		tagVal := field.Tag.Get(f.tag)
+		if tagVal == "-" {
+			continue
+		}
+		name, opts := parseTag(tagVal)
+		fieldVal := v.Field(i)
+		if field.Anonymous && name == "" {
+			embedded := fieldVal
+			if embedded.Kind() == reflect.Ptr {
+				if embedded.IsNil() {
+					continue
+				}
+				if embedded.Elem().Kind() == reflect.Struct {
+					if !f.enter(embedded) {
+						ret[field.Name] = CYCLE_PLACEHOLDER
+						continue
+					}
+					f.flattenStruct(embedded.Elem(), depth+1, ret)
+					f.leave(embedded)
+					continue
+				}
+			}
+			if embedded.Kind() == reflect.Struct {
+				f.flattenStruct(embedded, depth, ret)
+				continue
+			}
+		}
+		if field.PkgPath != "" {
+			continue
+		}
+		if hasTagOption(opts, "omitempty") && isEmptyValue(fieldVal) {
+			continue
+		}
+		if name == "" {
+			name = field.Name
+		}
+		ret[name] = f.flatten(fieldVal, depth+1)
+	}
+}
@@ -0,0 +1,199 @@
+package logger
+
+import (
+	"encoding/json"
+	"errors"
+	"reflect"
+	"testing"
+	"time"
+)
+
+type testAddress struct {
+	City    string `json:"city" log:"town"`
+	ZipCode string `json:"zipCode,omitempty"`
+}
+
+type testAudit struct {
+	CreatedBy string `json:"createdBy"`
+}
+
+type testUser struct {
+	testAudit
+	Name      string            `json:"name" log:"fullName"`
+	Password  string            `json:"-" log:"-"`
+	Age       int               `json:"age,omitempty"`
+	Address   *testAddress      `json:"address"`
+	Tags      []string          `json:"tags"`
+	Scores    map[int]float64   `json:"scores,omitempty"`
+	Extra     map[string]string `json:"extra,omitempty"`
+	Untagged  bool
+	Dash      string `json:"-,"`
+	unexposed string
+}
+
+type testNode struct {
+	Name string    `json:"name"`
+	Next *testNode `json:"next"`
+}
+
+type panickingError struct{}
+
+func (*panickingError) Error() string {
+	panic("broken error")
+}
+
+var flattenedTime = time.Date(2021, 5, 1, 10, 30, 0, 0, time.UTC)
+
+var toLogDataTests = []struct {
+	testName string
+	data     interface{}
+	tag      string
+	want     map[string]interface{}
+}{
+	{"nil", nil, "", nil},
+	{"map", map[string]interface{}{"userId": "1234"}, "", map[string]interface{}{"userId": "1234"}},
+	{
+		"struct with json tags",
+		testUser{
+			testAudit: testAudit{CreatedBy: "admin"},
+			Name:      "juan",
+			Password:  "hunter2",
+			Address:   &testAddress{City: "Manila"},
+			Tags:      []string{"a", "b"},
+			Scores:    map[int]float64{1: 9.5},
+			Untagged:  true,
+			Dash:      "dash",
+			unexposed: "hidden",
+		},
+		"json",
+		map[string]interface{}{
+			"createdBy": "admin",
+			"name":      "juan",
+			"address":   map[string]interface{}{"city": "Manila"},
+			"tags":      []interface{}{"a", "b"},
+			"scores":    map[string]interface{}{"1": 9.5},
+			"Untagged":  true,
+			"-":         "dash",
+		},
+	},
+	{
+		"pointer to struct with custom tag",
+		&testUser{Name: "juan", Password: "hunter2", Age: 30, Address: &testAddress{City: "Manila", ZipCode: "1000"}},
+		"log",
+		map[string]interface{}{
+			"CreatedBy": "",
+			"fullName":  "juan",
+			"Age":       30,
+			"Address":   map[string]interface{}{"town": "Manila", "ZipCode": "1000"},
+			"Tags":      nil,
+			"Scores":    nil,
+			"Extra":     nil,
+			"Untagged":  false,
+			"Dash":      "",
+		},
+	},
+	{"default tag", testAddress{City: "Manila"}, "", map[string]interface{}{"city": "Manila"}},
+	{"scalar", 42, "", map[string]interface{}{"value": 42}},
+	{
+		"slice of structs",
+		[]testAddress{{City: "Manila"}, {City: "Cebu"}},
+		"",
+		map[string]interface{}{"value": []interface{}{
+			map[string]interface{}{"city": "Manila"},
+			map[string]interface{}{"city": "Cebu"},
+		}},
+	},
+	{"nil pointer", (*testUser)(nil), "", nil},
+	{"error", errors.New("not found"), "", map[string]interface{}{"value": "not found"}},
+	{
+		"nested error and time",
+		map[string]interface{}{"err": errors.New("not found"), "at": flattenedTime, "fn": func() {}},
+		"",
+		map[string]interface{}{"err": "not found", "at": flattenedTime, "fn": "func()"},
+	},
+	{"panicking error", &panickingError{}, "", map[string]interface{}{"logDataError": "broken error"}},
+}
+
+func TestToLogData(t *testing.T) {
+	for _, tt := range toLogDataTests {
+		t.Run(tt.testName, func(t *testing.T) {
+			got := toLogData(tt.data, tt.tag)
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("log data got %#v, want %#v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestToLogDataCycles(t *testing.T) {
+	first := &testNode{Name: "first"}
+	first.Next = &testNode{Name: "second", Next: first}
+	want := map[string]interface{}{
+		"name": "first",
+		"next": map[string]interface{}{"name": "second", "next": CYCLE_PLACEHOLDER},
+	}
+	if got := toLogData(first, ""); !reflect.DeepEqual(got, want) {
+		t.Errorf("cyclic struct log data got %v, want %v", got, want)
+	}
+
+	cyclicMap := map[string]interface{}{"name": "root"}
+	cyclicMap["self"] = cyclicMap
+	got := toLogData(cyclicMap, "")
+	if got["self"] != CYCLE_PLACEHOLDER {
+		t.Errorf("cyclic map log data got %v", got)
+	}
+	if _, err := json.Marshal(got); err != nil {
+		t.Errorf("cyclic log data can't be formatted as json: %v", err)
+	}
+
+	shared := &testAddress{City: "Manila"}
+	got = toLogData(map[string]interface{}{"home": shared, "work": shared}, "")
+	if got["home"].(map[string]interface{})["city"] != "Manila" || got["work"].(map[string]interface{})["city"] != "Manila" {
+		t.Errorf("shared pointers should not be reported as cycles, got %v", got)
+	}
+}
+
+// selfEmbedded embeds a pointer to its own type
+type selfEmbedded struct {
+	*selfEmbedded
+	Val int
+}
+
+func TestToLogDataEmbeddedCycles(t *testing.T) {
+	s := &selfEmbedded{Val: 1}
+	s.selfEmbedded = s
+	want := map[string]interface{}{"selfEmbedded": CYCLE_PLACEHOLDER, "Val": 1}
+	for _, data := range []interface{}{s, *s} {
+		if got := toLogData(data, ""); !reflect.DeepEqual(got, want) {
+			t.Errorf("self embedding struct log data got %v, want %v", got, want)
+		}
+	}
+
+	shared := &selfEmbedded{Val: 2}
+	got := toLogData([]interface{}{selfEmbedded{selfEmbedded: shared}, selfEmbedded{selfEmbedded: shared}}, "")
+	wantShared := map[string]interface{}{"value": []interface{}{
+		map[string]interface{}{"Val": 0}, map[string]interface{}{"Val": 0},
+	}}
+	if !reflect.DeepEqual(got, wantShared) {
+		t.Errorf("shared embedded pointers should not be reported as cycles, got %v", got)
+	}
+}
+
+func TestToLogDataMaxDepth(t *testing.T) {
+	var root interface{} = "leaf"
+	for i := 0; i < MAX_FLATTEN_DEPTH+5; i++ {
+		root = []interface{}{root}
+	}
+	got := toLogData(root, "")
+	value := got["value"]
+	for depth := 0; ; depth++ {
+		list, ok := value.([]interface{})
+		if !ok {
+			if value != MAX_DEPTH_PLACEHOLDER {
+				t.Errorf("deep log data got %v at depth %v, want the max depth placeholder", value, depth)
+			}
+			break
+		}
+		value = list[0]
+	}
+}
@@ -0,0 +1,113 @@
+package logger
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"strings"
+	"time"
+)
+
+// LogFormat is the output format of the displayed logs.
+type LogFormat int
+
+const (
+	TEXT_FORMAT LogFormat = iota
+	JSON_FORMAT
+	CONSOLE_FORMAT
+)
+
+// FunctionName and FunctionVersion are the name and version of the running lambda function.
+// They are included in the JSON format logs.
+var (
+	FunctionName    = os.Getenv("AWS_LAMBDA_FUNCTION_NAME")
+	FunctionVersion = os.Getenv("AWS_LAMBDA_FUNCTION_VERSION")
+)
+
+// formatText will format the log as a line of text. The app data is appended as JSON followed by the
+// request, correlation and trace ids, the caller and the error chain, and the stack of a recovered panic
+// on the following lines.
+func formatText(lg Log) string {
+	appdata, _ := json.Marshal(lg.Data)
+	logEntryText := fmt.Sprintf(
+		"%v %v<%v> %v ",
+		lg.TimeStamp,
+		logLevelMap[lg.LogLevel],
+		lg.ModuleName,
+		lg.Text,
+	)
+	sep := ""
+	if string(appdata) != "null" {
+		logEntryText += string(appdata)
+		sep = " "
+	}
+	for _, id := range []struct{ key, value string }{
+		{"requestId", lg.RequestID},
+		{"correlationId", lg.CorrelationID},
+		{"traceId", lg.TraceID},
+	} {
+		if id.value != "" {
+			logEntryText += sep + id.key + "=" + id.value
+			sep = " "
+		}
+	}
+	if lg.Caller != "" {
+		logEntryText += sep + "caller=" + lg.Caller
+		sep = " "
+	}
+	if len(lg.Errors) > 0 {
+		errorChain, _ := json.Marshal(lg.Errors)
+		logEntryText += sep + "errors=" + string(errorChain)
+	}
+	if lg.Stack != "" {
+		logEntryText += "\n" + strings.TrimRight(lg.Stack, "\n")
+	}
+	return logEntryText
+}
+
+// formatJSON will format the log as a single line JSON object that CloudWatch Logs Insights can query.
+func formatJSON(lg Log) string {
+	entry := map[string]interface{}{
+		"level":     lg.LogLevel.String(),
+		"module":    lg.ModuleName,
+		"timestamp": lg.Time.Format(time.RFC3339Nano),
+		"message":   lg.Text,
+	}
+	if lg.Data != nil {
+		entry["data"] = lg.Data
+	}
+	if lg.RequestID != "" {
+		entry["requestId"] = lg.RequestID
+	}
+	if lg.CorrelationID != "" {
+		entry["correlationId"] = lg.CorrelationID
+	}
+	if lg.TraceID != "" {
+		entry["traceId"] = lg.TraceID
+	}
+	if lg.SpanID != "" {
+		entry["spanId"] = lg.SpanID
+	}
+	if lg.Caller != "" {
+		entry["caller"] = lg.Caller
+	}
+	if lg.Stack != "" {
+		entry["stack"] = lg.Stack
+	}
+	if len(lg.Errors) > 0 {
+		entry["errors"] = lg.Errors
+	}
+	if FunctionName != "" {
+		entry["functionName"] = FunctionName
+	}
+	if FunctionVersion != "" {
+		entry["functionVersion"] = FunctionVersion
+	}
+	jsonLine, err := json.Marshal(entry)
+	if err != nil {
+		// Data that can't be marshaled is logged as its string form
+		entry["data"] = fmt.Sprintf("%v", lg.Data)
+		jsonLine, _ = json.Marshal(entry)
+	}
+	return string(jsonLine)
+}
@@ -0,0 +1,152 @@
+package logger
+
+import (
+	"bufio"
+	"encoding/json"
+	"io/ioutil"
+	"os"
+	"strings"
+	"testing"
+	"time"
+)
+
+// captureStdout will return everything fn prints to stdout
+func captureStdout(fn func()) string {
+	stdout := os.Stdout
+	r, w, _ := os.Pipe()
+	os.Stdout = w
+	out := make(chan string)
+	go func() {
+		b, _ := ioutil.ReadAll(r)
+		out <- string(b)
+	}()
+	fn()
+	w.Close()
+	os.Stdout = stdout
+	return <-out
+}
+
+func TestFormatJSON(t *testing.T) {
+	FunctionName, FunctionVersion = "create_user", "$LATEST"
+	defer func() { FunctionName, FunctionVersion = "", "" }()
+
+	now := time.Date(2021, 5, 1, 10, 30, 0, 123456789, time.UTC)
+	got := map[string]interface{}{}
+	err := json.Unmarshal([]byte(formatJSON(Log{
+		LogLevel:      WARN,
+		ModuleName:    "servicehandler.NewServiceEndpoint",
+		Time:          now,
+		Text:          "Test warning log",
+		Data:          map[string]interface{}{"userId": "1234"},
+		RequestID:     "request-1",
+		CorrelationID: "correlation-1",
+		TraceID:       "4bf92f3577b34da6a3ce929d0e0e4736",
+		SpanID:        "00f067aa0ba902b7",
+	})), &got)
+	if err != nil {
+		t.Fatalf("json format is not valid json: %v", err)
+	}
+	want := map[string]interface{}{
+		"level":           "WARN",
+		"module":          "servicehandler.NewServiceEndpoint",
+		"timestamp":       "2021-05-01T10:30:00.123456789Z",
+		"message":         "Test warning log",
+		"data":            map[string]interface{}{"userId": "1234"},
+		"requestId":       "request-1",
+		"correlationId":   "correlation-1",
+		"traceId":         "4bf92f3577b34da6a3ce929d0e0e4736",
+		"spanId":          "00f067aa0ba902b7",
+		"functionName":    "create_user",
+		"functionVersion": "$LATEST",
+	}
+	for k, v := range want {
+		if k == "data" {
+			if got[k].(map[string]interface{})["userId"] != "1234" {
+				t.Errorf("json format data got %v", got[k])
+			}
+			continue
+		}
+		if got[k] != v {
+			t.Errorf("json format %v got %v, want %v", k, got[k], v)
+		}
+	}
+}
+
+func TestFormatJSONUnsupportedData(t *testing.T) {
+	line := formatJSON(Log{LogLevel: INFO, Data: map[string]interface{}{"fn": func() {}}})
+	if !json.Valid([]byte(line)) {
+		t.Errorf("json format with unsupported data is not valid json: %v", line)
+	}
+}
+
+var formatTextTests = []struct {
+	testName string
+	log      Log
+	want     string
+}{
+	{
+		"data",
+		Log{Data: map[string]interface{}{"userId": "1234"}},
+		`Saturday, 01-May-21 10:30:00 UTC ERROR<logger.TestFormatText> Test error log {"userId":"1234"}`,
+	},
+	{
+		"data and ids",
+		Log{
+			Data:          map[string]interface{}{"userId": "1234"},
+			RequestID:     "request-1",
+			CorrelationID: "correlation-1",
+			TraceID:       "4bf92f3577b34da6a3ce929d0e0e4736",
+			SpanID:        "00f067aa0ba902b7",
+			Caller:        "main.go:10",
+		},
+		`Saturday, 01-May-21 10:30:00 UTC ERROR<logger.TestFormatText> Test error log {"userId":"1234"} ` +
+			`requestId=request-1 correlationId=correlation-1 traceId=4bf92f3577b34da6a3ce929d0e0e4736 caller=main.go:10`,
+	},
+	{
+		"correlation id without data",
+		Log{CorrelationID: "correlation-1"},
+		`Saturday, 01-May-21 10:30:00 UTC ERROR<logger.TestFormatText> Test error log correlationId=correlation-1`,
+	},
+}
+
+func TestFormatText(t *testing.T) {
+	for _, tt := range formatTextTests {
+		t.Run(tt.testName, func(t *testing.T) {
+			lg := tt.log
+			lg.LogLevel = ERROR
+			lg.ModuleName = "logger.TestFormatText"
+			lg.TimeStamp = "Saturday, 01-May-21 10:30:00 UTC"
+			lg.Text = "Test error log"
+			if line := formatText(lg); line != tt.want {
+				t.Errorf("text format got %v, want %v", line, tt.want)
+			}
+		})
+	}
+}
+
+func TestDisplayLogsJSONFormat(t *testing.T) {
+	logger := NewLogger()
+	logger.Format = JSON_FORMAT
+	logger.RequestID = "request-1"
+	logger.LogTxt(INFO, "Test info log")
+	logger.LogObj(DEBUG, "Test debug log", map[string]interface{}{"test": "test"}, "", false)
+
+	for _, display := range []func(){logger.DisplayLogsBackward, logger.DisplayLogsForward} {
+		out := captureStdout(display)
+		lines := 0
+		scanner := bufio.NewScanner(strings.NewReader(out))
+		for scanner.Scan() {
+			entry := map[string]interface{}{}
+			if err := json.Unmarshal(scanner.Bytes(), &entry); err != nil {
+				t.Fatalf("displayed log is not a json line: %v", scanner.Text())
+			}
+			if entry["requestId"] != "request-1" {
+				t.Errorf("displayed log missing request id: %v", scanner.Text())
+			}
+			lines++
+		}
+		if lines != 2 {
+			t.Errorf("displayed %v json lines, want 2", lines)
+		}
+	}
+}
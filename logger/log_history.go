@@ -0,0 +1,187 @@
+package logger
+
+import (
+	"fmt"
+	"sync"
+)
+
+// DEFAULT_MAX_LOG_ENTRIES is the capacity of the log history of new loggers
+const DEFAULT_MAX_LOG_ENTRIES = 10000
+
+// historyEntry is a log recorded in the LogHistory with its estimated size in bytes.
+type historyEntry struct {
+	log  Log
+	size int
+}
+
+// LogHistory is the object for recording logs in a bounded ring buffer. When MaxEntries or
+// MaxBytes is reached the oldest logs are dropped. A zero limit is unbounded. It also counts the logs
+// suppressed by the rate limiter, so each derived logger summarizes its own suppressed logs.
+// It is safe for concurrent use.
+type LogHistory struct {
+	mu         sync.Mutex
+	MaxEntries int
+	MaxBytes   int
+	entries    []historyEntry
+	start      int
+	size       int
+	bytes      int
+	dropped    int
+	suppressed map[string]int
+}
+
+// NewLogHistory will create a log history bounded to maxEntries logs and maxBytes bytes.
+func NewLogHistory(maxEntries int, maxBytes int) *LogHistory {
+	return &LogHistory{
+		MaxEntries: maxEntries,
+		MaxBytes:   maxBytes,
+	}
+}
+
+// logSize will estimate the size in bytes of a log.
+func logSize(lg Log) int {
+	size := len(lg.ModuleName) + len(lg.TimeStamp) + len(lg.Text) + len(lg.RequestID) +
+		len(lg.CorrelationID) + len(lg.TraceID) + len(lg.SpanID) + len(lg.Caller) + len(lg.Stack)
+	for k, v := range lg.Data {
+		size += len(k) + len(fmt.Sprint(v))
+	}
+	for _, e := range lg.Errors {
+		size += len(e.Message) + len(e.Type)
+	}
+	return size
+}
+
+// at will return the index in the ring buffer of the i-th oldest log.
+func (lh *LogHistory) at(i int) int {
+	return (lh.start + i) % len(lh.entries)
+}
+
+// dropOldest will remove the oldest log of the log history.
+func (lh *LogHistory) dropOldest() {
+	oldest := lh.start
+	lh.bytes -= lh.entries[oldest].size
+	lh.entries[oldest] = historyEntry{}
+	lh.start = (lh.start + 1) % len(lh.entries)
+	lh.size--
+	lh.dropped++
+}
+
+// grow will double the ring buffer up to MaxEntries, moving the logs to the start of the buffer.
+func (lh *LogHistory) grow() {
+	capacity := 2 * len(lh.entries)
+	if capacity == 0 {
+		capacity = 16
+	}
+	if lh.MaxEntries > 0 && capacity > lh.MaxEntries {
+		capacity = lh.MaxEntries
+	}
+	entries := make([]historyEntry, capacity)
+	for i := 0; i < lh.size; i++ {
+		entries[i] = lh.entries[lh.at(i)]
+	}
+	lh.entries = entries
+	lh.start = 0
+}
+
+// Append will record a log as the newest log of the log history, dropping the oldest logs over the limits.
+// A log larger than MaxBytes is dropped.
+func (lh *LogHistory) Append(lg Log) {
+	lh.mu.Lock()
+	defer lh.mu.Unlock()
+	size := 0
+	if lh.MaxBytes > 0 {
+		size = logSize(lg)
+		if size > lh.MaxBytes {
+			lh.dropped++
+			return
+		}
+		for lh.size > 0 && lh.bytes+size > lh.MaxBytes {
+			lh.dropOldest()
+		}
+	}
+	if lh.size == len(lh.entries) {
+		if lh.MaxEntries > 0 && lh.size >= lh.MaxEntries {
+			lh.dropOldest()
+		} else {
+			lh.grow()
+		}
+	}
+	lh.entries[lh.at(lh.size)] = historyEntry{log: lg, size: size}
+	lh.size++
+	lh.bytes += size
+}
+
+// Len will return the number of logs recorded in the log history.
+func (lh *LogHistory) Len() int {
+	lh.mu.Lock()
+	defer lh.mu.Unlock()
+	return lh.size
+}
+
+// Dropped will return the number of logs dropped from the log history over its limits.
+func (lh *LogHistory) Dropped() int {
+	lh.mu.Lock()
+	defer lh.mu.Unlock()
+	return lh.dropped
+}
+
+// Suppress will count a log of the message suppressed by the rate limiter.
+func (lh *LogHistory) Suppress(msg string) {
+	lh.mu.Lock()
+	defer lh.mu.Unlock()
+	if lh.suppressed == nil {
+		lh.suppressed = map[string]int{}
+	}
+	lh.suppressed[msg]++
+}
+
+// TakeSuppressed will return the number of suppressed logs by message since the last call and reset them.
+func (lh *LogHistory) TakeSuppressed() map[string]int {
+	lh.mu.Lock()
+	defer lh.mu.Unlock()
+	suppressed := lh.suppressed
+	lh.suppressed = nil
+	return suppressed
+}
+
+// Clear will remove all the logs recorded in the log history.
+func (lh *LogHistory) Clear() {
+	lh.mu.Lock()
+	defer lh.mu.Unlock()
+	lh.entries = nil
+	lh.start = 0
+	lh.size = 0
+	lh.bytes = 0
+}
+
+// Logs will return a copy of the logs recorded in the log history in historical order.
+func (lh *LogHistory) Logs() []Log {
+	lh.mu.Lock()
+	defer lh.mu.Unlock()
+	logs := make([]Log, lh.size)
+	for i := range logs {
+		logs[i] = lh.entries[lh.at(i)].log
+	}
+	return logs
+}
+
+// Forward will call fn on each log from the newest to the oldest until fn returns false.
+// It iterates over a snapshot so fn may log to the same history.
+func (lh *LogHistory) Forward(fn func(lg Log) bool) {
+	logs := lh.Logs()
+	for i := len(logs) - 1; i >= 0; i-- {
+		if !fn(logs[i]) {
+			return
+		}
+	}
+}
+
+// Backward will call fn on each log from the oldest to the newest, in historical order,
+// until fn returns false. It iterates over a snapshot so fn may log to the same history.
+func (lh *LogHistory) Backward(fn func(lg Log) bool) {
+	for _, lg := range lh.Logs() {
+		if !fn(lg) {
+			return
+		}
+	}
+}
@@ -0,0 +1,191 @@
+package logger
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+	"sync"
+	"testing"
+)
+
+// logTexts will return the texts of the logs in the order given by the iterator
+func logTexts(iterate func(fn func(lg Log) bool)) []string {
+	texts := []string{}
+	iterate(func(lg Log) bool {
+		texts = append(texts, lg.Text)
+		return true
+	})
+	return texts
+}
+
+var logHistoryTests = []struct {
+	testName     string
+	maxEntries   int
+	maxBytes     int
+	texts        []string
+	wantBackward []string
+	wantDropped  int
+	lastStack    string
+}{
+	{"unbounded", 0, 0, []string{"a", "b", "c"}, []string{"a", "b", "c"}, 0, ""},
+	{"below max entries", 5, 0, []string{"a", "b", "c"}, []string{"a", "b", "c"}, 0, ""},
+	{"max entries", 2, 0, []string{"a", "b", "c", "d", "e"}, []string{"d", "e"}, 3, ""},
+	{"max bytes", 0, 3, []string{"a", "b", "c", "d"}, []string{"b", "c", "d"}, 1, ""},
+	{"max bytes larger log", 0, 3, []string{"a", "b", "c", "de"}, []string{"c", "de"}, 2, ""},
+	{"log larger than max bytes", 0, 3, []string{"a", "long"}, []string{"a"}, 1, ""},
+	{"max entries and bytes", 2, 4, []string{"a", "bc", "de", "f"}, []string{"de", "f"}, 2, ""},
+	{"max bytes with stack", 0, 10, []string{"a", "b", "c"}, []string{"b", "c"}, 1, "panic: x"},
+	{"stack larger than max bytes", 0, 10, []string{"a", "b"}, []string{"a"}, 1, strings.Repeat("goroutine", 100)},
+}
+
+func TestLogHistory(t *testing.T) {
+	for _, tt := range logHistoryTests {
+		t.Run(tt.testName, func(t *testing.T) {
+			lh := NewLogHistory(tt.maxEntries, tt.maxBytes)
+			for i, text := range tt.texts {
+				lg := Log{Text: text}
+				if i == len(tt.texts)-1 {
+					lg.Stack = tt.lastStack
+				}
+				lh.Append(lg)
+			}
+			if got := logTexts(lh.Backward); !reflect.DeepEqual(got, tt.wantBackward) {
+				t.Errorf("backward logs got %v, want %v", got, tt.wantBackward)
+			}
+			wantForward := []string{}
+			for i := len(tt.wantBackward) - 1; i >= 0; i-- {
+				wantForward = append(wantForward, tt.wantBackward[i])
+			}
+			if got := logTexts(lh.Forward); !reflect.DeepEqual(got, wantForward) {
+				t.Errorf("forward logs got %v, want %v", got, wantForward)
+			}
+			if lh.Len() != len(tt.wantBackward) || lh.Dropped() != tt.wantDropped {
+				t.Errorf("log history len %v dropped %v, want %v and %v",
+					lh.Len(), lh.Dropped(), len(tt.wantBackward), tt.wantDropped)
+			}
+		})
+	}
+}
+
+func TestLogHistoryWrapAround(t *testing.T) {
+	lh := NewLogHistory(40, 0)
+	want := []string{}
+	for i := 0; i < 100; i++ {
+		lh.Append(Log{Text: fmt.Sprint(i)})
+		if i >= 60 {
+			want = append(want, fmt.Sprint(i))
+		}
+	}
+	if got := logTexts(lh.Backward); !reflect.DeepEqual(got, want) {
+		t.Errorf("wrapped logs got %v, want %v", got, want)
+	}
+	if len(lh.entries) != 40 {
+		t.Errorf("ring buffer capacity got %v, want 40", len(lh.entries))
+	}
+}
+
+func TestLogHistorySuppressed(t *testing.T) {
+	lh := NewLogHistory(0, 0)
+	if suppressed := lh.TakeSuppressed(); suppressed != nil {
+		t.Errorf("suppressed counts got %v, want none", suppressed)
+	}
+	lh.Suppress("Fetching user")
+	lh.Suppress("Fetching user")
+	lh.Suppress("Saving user")
+	if suppressed := lh.TakeSuppressed(); !reflect.DeepEqual(suppressed, map[string]int{"Fetching user": 2, "Saving user": 1}) {
+		t.Errorf("suppressed counts got %v", suppressed)
+	}
+	if suppressed := lh.TakeSuppressed(); suppressed != nil {
+		t.Errorf("suppressed counts not reset, got %v", suppressed)
+	}
+}
+
+func TestLogHistoryIteratorStop(t *testing.T) {
+	lh := NewLogHistory(0, 0)
+	for _, text := range []string{"a", "b", "c"} {
+		lh.Append(Log{Text: text})
+	}
+	visited := 0
+	lh.Backward(func(lg Log) bool {
+		visited++
+		return lg.Text != "b"
+	})
+	if visited != 2 {
+		t.Errorf("iterator visited %v logs after stop, want 2", visited)
+	}
+}
+
+func TestLoggerDeriveLogHistoryLimits(t *testing.T) {
+	lgr := NewLogger()
+	lgr.LogHistory = NewLogHistory(2, 1000)
+	derived := lgr.Derive()
+	if derived.LogHistory.MaxEntries != 2 || derived.LogHistory.MaxBytes != 1000 {
+		t.Errorf("derived log history limits not kept")
+	}
+	for i := 0; i < 3; i++ {
+		derived.LogTxt(INFO, "Test derived log")
+	}
+	if derived.LogHistory.Len() != 2 || derived.LogHistory.Dropped() != 1 {
+		t.Errorf("derived log history not bounded")
+	}
+}
+
+// linkedNode is a node of the linked list log history that LogHistory replaced, kept for benchmarks.
+type linkedNode struct {
+	prev *linkedNode
+	next *linkedNode
+	log  Log
+}
+
+// linkedLogHistory is the linked list log history that LogHistory replaced, kept for benchmarks.
+type linkedLogHistory struct {
+	mu   sync.Mutex
+	head *linkedNode
+	tail *linkedNode
+	size int
+}
+
+// insert will insert a log into the linked list, walking to its tail on every insert.
+func (lh *linkedLogHistory) insert(lg Log) {
+	lh.mu.Lock()
+	defer lh.mu.Unlock()
+	node := &linkedNode{log: lg, next: lh.head}
+	if lh.head != nil {
+		lh.head.prev = node
+	}
+	lh.head = node
+	lh.size++
+	l := lh.head
+	for l.next != nil {
+		l = l.next
+	}
+	lh.tail = l
+}
+
+var benchmarkLog = Log{LogLevel: INFO, ModuleName: "logger.Benchmark", Text: strings.Repeat("x", 64)}
+
+func BenchmarkLogHistoryAppend(b *testing.B) {
+	for _, n := range []int{100, 1000, 10000} {
+		b.Run(fmt.Sprint(n), func(b *testing.B) {
+			for i := 0; i < b.N; i++ {
+				lh := NewLogHistory(DEFAULT_MAX_LOG_ENTRIES, 0)
+				for j := 0; j < n; j++ {
+					lh.Append(benchmarkLog)
+				}
+			}
+		})
+	}
+}
+
+func BenchmarkLinkedLogHistoryInsert(b *testing.B) {
+	for _, n := range []int{100, 1000, 10000} {
+		b.Run(fmt.Sprint(n), func(b *testing.B) {
+			for i := 0; i < b.N; i++ {
+				lh := &linkedLogHistory{}
+				for j := 0; j < n; j++ {
+					lh.insert(benchmarkLog)
+				}
+			}
+		})
+	}
+}
@@ -1,187 +1,217 @@
 package logger
 
 import (
-	"encoding/json"
 	"fmt"
+	"os"
 	"reflect"
 	"runtime"
 	"strings"
 	"time"
 )
 
+// LogLevel is the severity of a log, ordered from the least to the most severe.
 type LogLevel int
 
 const (
-	INFO  LogLevel = iota
-	DEBUG          = iota
-	ERROR          = iota
-	WARN           = iota
-	FATAL          = iota
+	TRACE LogLevel = iota
+	DEBUG
+	INFO
+	WARN
+	ERROR
+	FATAL
 )
 
-// LogLevelMap is a mapping for LogLevel enum
-var logLevelMap = map[int]string{
-	int(INFO):  "INFO",
-	int(DEBUG): "DEBUG",
-	int(ERROR): "ERROR",
-	int(WARN):  "WARN",
-	int(FATAL): "FATAL",
+// LOG_LEVEL_ENV is the environment variable of the minimum log level of new loggers
+const LOG_LEVEL_ENV = "LOG_LEVEL"
+
+// logLevelMap is a mapping for LogLevel enum
+var logLevelMap = map[LogLevel]string{
+	TRACE: "TRACE",
+	DEBUG: "DEBUG",
+	INFO:  "INFO",
+	WARN:  "WARN",
+	ERROR: "ERROR",
+	FATAL: "FATAL",
 }
 
-// Log is the type of object that can be logged in the LogHistory.
-type Log struct {
-	LogLevel   LogLevel
-	ModuleName string
-	TimeStamp  string
-	Text       string
-	Data       map[string]interface{}
+// String will return the name of the LogLevel.
+func (logLvl LogLevel) String() string {
+	return logLevelMap[logLvl]
 }
 
-// Node is a node for implementing linked list in LogHistory.
-type Node struct {
-	prev *Node
-	next *Node
-	log  Log
+// ParseLogLevel will return the LogLevel of a case-insensitive level name like "debug".
+func ParseLogLevel(name string) (LogLevel, bool) {
+	for lvl, lvlName := range logLevelMap {
+		if strings.EqualFold(strings.TrimSpace(name), lvlName) {
+			return lvl, true
+		}
+	}
+	return TRACE, false
 }
 
-// LogHistory is the object for recording all logs in linkedlist fashion.
-type LogHistory struct {
-	head *Node
-	tail *Node
+// Log is the type of object that can be logged in the LogHistory.
+// Caller is the file:line of the ERROR and FATAL logs, Stack the goroutine stack of a recovered panic
+// and Errors the unwrapped chain of a logged error. TraceID and SpanID link the log to the span it was
+// logged in.
+type Log struct {
+	LogLevel      LogLevel
+	ModuleName    string
+	TimeStamp     string
+	Time          time.Time
+	Text          string
+	Data          map[string]interface{}
+	RequestID     string
+	CorrelationID string
+	TraceID       string
+	SpanID        string
+	Caller        string
+	Stack         string
+	Errors        []ErrorInfo
 }
 
 // Logger is an struct for logging.
+// Format selects how the logs are displayed, RequestID, CorrelationID, TraceID and SpanID are attached
+// to every log.
+// Sinks are the destinations of the displayed logs, stdout in the Format when empty.
+// Logs below MinLevel are discarded, Mode selects when the logs are written.
+// Fields are attached to the data of every log. Redactor masks the sensitive values of the logs
+// before they are recorded, nil disables the redaction. Sampler and RateLimiter drop the logs of
+// unsampled requests and the repeated logs over the limit, nil disables them.
 type Logger struct {
-	LogHistory *LogHistory
+	LogHistory    *LogHistory
+	Format        LogFormat
+	RequestID     string
+	CorrelationID string
+	TraceID       string
+	SpanID        string
+	Sinks         []Sink
+	MinLevel      LogLevel
+	Mode          LogMode
+	Fields        map[string]interface{}
+	Redactor      *Redactor
+	Sampler       *Sampler
+	RateLimiter   *RateLimiter
 }
 
 // NewLogger will create new Logger instance.
+// The minimum log level is read from the LOG_LEVEL environment variable, logging every level when unset.
+// The format is detected from the environment, see DetectFormat.
 func NewLogger() Logger {
+	minLvl, _ := ParseLogLevel(os.Getenv(LOG_LEVEL_ENV))
 	return Logger{
-		LogHistory: &LogHistory{},
+		LogHistory: NewLogHistory(DEFAULT_MAX_LOG_ENTRIES, 0),
+		Format:     DetectFormat(),
+		MinLevel:   minLvl,
+		Redactor:   defaultRedactor,
 	}
 }
 
-// structToMap converts struct to map[string]interface{}.
+// Enabled will check if logs of the log level are recorded by the logger.
+func (lgr Logger) Enabled(logLvl LogLevel) bool {
+	return isLevelEnabled(logLvl, lgr.MinLevel)
+}
+
+// Derive will create a new Logger with the configuration of lgr and an empty LogHistory.
+// It is used to create a request-scoped logger per invocation from a base logger.
+func (lgr Logger) Derive() Logger {
+	derived := lgr
+	derived.LogHistory = NewLogHistory(lgr.LogHistory.MaxEntries, lgr.LogHistory.MaxBytes)
+	return derived
+}
+
+// structToMap converts a struct or a pointer to a struct to map[string]interface{}.
 func structToMap(in interface{}, tag string) (map[string]interface{}, error) {
-	ret := make(map[string]interface{})
 	mapVal := reflect.ValueOf(in)
+	if mapVal.Kind() == reflect.Ptr && !mapVal.IsNil() {
+		mapVal = mapVal.Elem()
+	}
 	if mapVal.Kind() != reflect.Struct {
 		return nil, fmt.Errorf("invalid input struct")
 	}
-	for i := 0; i < mapVal.Type().NumField(); i++ {
-		field := mapVal.Type().Field(i)
-		if tagVal := field.Tag.Get(tag); tagVal != "" {
-			ret[tagVal] = mapVal.Field(i).Interface()
-		}
-	}
-	return ret, nil
+	return toLogData(in, tag), nil
+}
+
+// newLog will create a log of the caller skip frames above newLog. The file:line of the caller is
+// recorded for ERROR and FATAL logs.
+func (lgr Logger) newLog(skip int, logLvl LogLevel, txt string, data map[string]interface{}) Log {
+	pc, file, line, _ := runtime.Caller(skip)
+	return lgr.newLogAt(runtime.FuncForPC(pc).Name(), file, line, logLvl, txt, data)
 }
 
-// insertNode will insert a node into the log history linked list
-func insertNode(node *Node, lh *LogHistory) {
-	head := lh.head
-	list := node
-	if head != nil {
-		lh.head.prev = list
+// newLogAt will create a log of the caller function at file:line.
+func (lgr Logger) newLogAt(callerName string, file string, line int, logLvl LogLevel, txt string,
+	data map[string]interface{}) Log {
+	callerNameSegment := strings.Split(callerName, "/")
+	now := time.Now()
+	lg := Log{
+		LogLevel:      logLvl,
+		TimeStamp:     now.Format(time.RFC850),
+		Time:          now,
+		ModuleName:    callerNameSegment[len(callerNameSegment)-1],
+		Text:          txt,
+		Data:          lgr.withFields(data),
+		RequestID:     lgr.RequestID,
+		CorrelationID: lgr.CorrelationID,
+		TraceID:       lgr.TraceID,
+		SpanID:        lgr.SpanID,
 	}
-	lh.head = list
-	l := lh.head
-	for l.next != nil {
-		l = l.next
+	if logLvl >= ERROR {
+		lg.Caller = callerLocation(file, line)
 	}
-	lh.tail = l
+	return lg
 }
 
-// LogObj will insert new log with additional app data (data interface{}) into log history in a linked-list fashion.
-// It will panic if given a isStruct = true value and the data value fed isn't a struct.
-// The data parameter expects a map[string]interface{} unless stated via isStruct.
-// It will transform the struct to map and collate all the fields with the dataTag provided.
+// LogObj will insert new log with additional app data (data interface{}) into log history.
+// The data can be any value: structs, pointers, maps, slices and scalars are flattened recursively into
+// the log data, with the struct fields named by the dataTag (json when empty) honouring omitempty and "-".
+// Scalars and slices are stored under the "value" key. It never panics. isStruct is no longer needed and
+// is kept for compatibility.
 func (lgr Logger) LogObj(logLvl LogLevel, txt string, data interface{}, dataTag string, isStruct bool) {
-	var dataMap map[string]interface{}
-	pc, _, _, _ := runtime.Caller(1)
-	callerName := runtime.FuncForPC(pc).Name()
-	callerNameSegment := strings.Split(callerName, "/")
-	if isStruct {
-		var err interface{}
-		dataMap, err = structToMap(data, "json")
-		if err != nil {
-			panic("Invalid Log Data. Data should be of type Struct")
-		}
-	} else {
-		dataMap = data.(map[string]interface{})
-	}
-	node := &Node{
-		next: lgr.LogHistory.head,
-		log: Log{
-			LogLevel:   logLvl,
-			TimeStamp:  time.Now().Format(time.RFC850),
-			ModuleName: callerNameSegment[len(callerNameSegment)-1],
-			Text:       txt,
-			Data:       dataMap,
-		},
+	if !lgr.Enabled(logLvl) {
+		return
 	}
-	insertNode(node, lgr.LogHistory)
+	lgr.record(lgr.newLog(2, logLvl, txt, toLogData(data, dataTag)))
 }
 
 // LogTxt will insert a new log text into log hisotry in a linked-list fashion.
 func (lgr Logger) LogTxt(logLvl LogLevel, txt string) {
-	pc, _, _, _ := runtime.Caller(1)
-	callerName := runtime.FuncForPC(pc).Name()
-	callerNameSegment := strings.Split(callerName, "/")
-	node := &Node{
-		next: lgr.LogHistory.head,
-		log: Log{
-			LogLevel:   logLvl,
-			TimeStamp:  time.Now().Format(time.RFC850),
-			ModuleName: callerNameSegment[len(callerNameSegment)-1],
-			Text:       txt,
-			Data:       nil,
-		},
+	if !lgr.Enabled(logLvl) {
+		return
 	}
-	insertNode(node, lgr.LogHistory)
+	lgr.record(lgr.newLog(2, logLvl, txt, nil))
 }
 
 // DisplayLogs will display all saved logs using fmt.Printf
 func (lgr Logger) DisplayLogsForward() {
-	list := lgr.LogHistory.head
-	for list != nil {
+	lgr.LogHistory.Forward(func(lg Log) bool {
+		if len(lgr.Sinks) > 0 || lgr.Format != TEXT_FORMAT {
+			lgr.writeLog(lg)
+			return true
+		}
 		appdata := ""
-		if list.log.Data != nil {
+		if lg.Data != nil {
 			appdata = "{"
-			for k, v := range list.log.Data {
+			for k, v := range lg.Data {
 				appdata += fmt.Sprintf("\t%v: %v\n", k, v)
 			}
 			appdata += "}"
 		}
 		fmt.Printf(
 			"%v %v<%v> %v %v\n",
-			list.log.TimeStamp,
-			logLevelMap[int(list.log.LogLevel)],
-			list.log.ModuleName,
-			list.log.Text,
+			lg.TimeStamp,
+			logLevelMap[lg.LogLevel],
+			lg.ModuleName,
+			lg.Text,
 			appdata,
 		)
-		list = list.next
-	}
+		return true
+	})
 }
 
 // DisplayLogsBackward  will display all saved logs using fmt.Printf in historical order
 func (lgr Logger) DisplayLogsBackward() {
-	list := lgr.LogHistory.tail
-	for list != nil {
-		appdata, _ := json.Marshal(list.log.Data)
-		logEntryText := fmt.Sprintf(
-			"%v %v<%v> %v ",
-			list.log.TimeStamp,
-			logLevelMap[int(list.log.LogLevel)],
-			list.log.ModuleName,
-			list.log.Text,
-		)
-		if string(appdata) != "null" {
-			logEntryText += string(appdata)
-		}
-		fmt.Println(logEntryText)
-		list = list.prev
-	}
+	lgr.LogHistory.Backward(func(lg Log) bool {
+		lgr.writeLog(lg)
+		return true
+	})
 }
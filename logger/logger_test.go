@@ -2,6 +2,8 @@ package logger
 
 import (
 	"fmt"
+	"os"
+	"sync"
 	"testing"
 )
 
@@ -64,9 +66,142 @@ func TestStructToMapInvalidMethod(t *testing.T) {
 func TestLoggerInvalidStruct(t *testing.T) {
 	logger := NewLogger()
 	defer func() {
-		if err := recover(); err == nil {
-			t.Errorf("invalid struct checking failed")
+		if err := recover(); err != nil {
+			t.Errorf("logging a pointer to a struct panicked: %v", err)
 		}
 	}()
-	logger.LogObj(INFO, "test", &TestObj{}, "test", true)
+	logger.LogObj(INFO, "test", &TestObj{Param1: "test1"}, "test", true)
+	logger.LogObj(INFO, "test", "not a struct", "json", true)
+	logs := logger.LogHistory.Logs()
+	if logs[0].Data["Param1"] != "test1" || logs[1].Data["value"] != "not a struct" {
+		t.Errorf("invalid struct log data got %v and %v", logs[0].Data, logs[1].Data)
+	}
+}
+
+func TestLoggerDerive(t *testing.T) {
+	logger := NewLogger()
+	logger.LogTxt(INFO, "Test base log")
+
+	derived := logger.Derive()
+	if derived.LogHistory == logger.LogHistory || derived.LogHistory.Len() != 0 {
+		t.Errorf("derived logger should have its own empty log history")
+	}
+	derived.LogTxt(INFO, "Test derived log")
+	derived.LogTxt(DEBUG, "Test derived log")
+	if logger.LogHistory.Len() != 1 || derived.LogHistory.Len() != 2 {
+		t.Errorf("derived logger history not isolated from base logger")
+	}
+}
+
+func TestLogHistoryClear(t *testing.T) {
+	logger := NewLogger()
+	logger.LogTxt(INFO, "Test info log")
+	logger.LogTxt(WARN, "Test warning log")
+	logger.LogHistory.Clear()
+	if logger.LogHistory.Len() != 0 || len(logger.LogHistory.Logs()) != 0 {
+		t.Errorf("log history not cleared")
+	}
+	logger.LogTxt(INFO, "Test info log after clear")
+	if logs := logger.LogHistory.Logs(); len(logs) != 1 || logs[0].Text != "Test info log after clear" {
+		t.Errorf("log history invalid after clear")
+	}
+}
+
+func TestConcurrentLogging(t *testing.T) {
+	const goroutines, logsPerGoroutine = 20, 50
+	logger := NewLogger()
+	var wg sync.WaitGroup
+	for i := 0; i < goroutines; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			for j := 0; j < logsPerGoroutine; j++ {
+				if j%2 == 0 {
+					logger.LogTxt(INFO, fmt.Sprintf("Test concurrent log %d-%d", i, j))
+				} else {
+					logger.LogObj(DEBUG, "Test concurrent log", map[string]interface{}{"i": i, "j": j}, "", false)
+				}
+			}
+		}(i)
+	}
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		logger.LogHistory.Len()
+		logger.Derive().LogTxt(INFO, "Test concurrent derived log")
+	}()
+	wg.Wait()
+
+	if logger.LogHistory.Len() != goroutines*logsPerGoroutine {
+		t.Errorf("log history len got %v, want %v", logger.LogHistory.Len(), goroutines*logsPerGoroutine)
+	}
+	forward, backward := 0, 0
+	logger.LogHistory.Forward(func(lg Log) bool {
+		forward++
+		return true
+	})
+	logger.LogHistory.Backward(func(lg Log) bool {
+		backward++
+		return true
+	})
+	if forward != goroutines*logsPerGoroutine || backward != forward {
+		t.Errorf("log history links corrupted, forward %v, backward %v", forward, backward)
+	}
+}
+
+var parseLogLevelTests = []struct {
+	testName  string
+	name      string
+	wantLevel LogLevel
+	wantOk    bool
+}{
+	{"upper case", "DEBUG", DEBUG, true},
+	{"lower case", "warn", WARN, true},
+	{"padded", " error ", ERROR, true},
+	{"trace", "trace", TRACE, true},
+	{"invalid", "verbose", TRACE, false},
+	{"empty", "", TRACE, false},
+}
+
+func TestParseLogLevel(t *testing.T) {
+	for _, tt := range parseLogLevelTests {
+		t.Run(tt.testName, func(t *testing.T) {
+			lvl, ok := ParseLogLevel(tt.name)
+			if lvl != tt.wantLevel || ok != tt.wantOk {
+				t.Errorf("parse log level got %v %v, want %v %v", lvl, ok, tt.wantLevel, tt.wantOk)
+			}
+		})
+	}
+}
+
+func TestLogLevelOrder(t *testing.T) {
+	levels := []LogLevel{TRACE, DEBUG, INFO, WARN, ERROR, FATAL}
+	for i := 1; i < len(levels); i++ {
+		if levels[i-1] >= levels[i] {
+			t.Errorf("log level %v should be less severe than %v", levels[i-1], levels[i])
+		}
+	}
+}
+
+func TestLoggerMinLevel(t *testing.T) {
+	os.Setenv(LOG_LEVEL_ENV, "warn")
+	defer os.Unsetenv(LOG_LEVEL_ENV)
+
+	logger := NewLogger()
+	if logger.MinLevel != WARN {
+		t.Fatalf("logger minimum level got %v, want %v", logger.MinLevel, WARN)
+	}
+	logger.LogTxt(DEBUG, "Test debug log")
+	logger.LogTxt(INFO, "Test info log")
+	logger.LogObj(INFO, "Test info log", map[string]interface{}{}, "", false)
+	logger.LogTxt(WARN, "Test warning log")
+	logger.LogTxt(ERROR, "Test error log")
+	if logger.LogHistory.Len() != 2 {
+		t.Errorf("logs below minimum level recorded, got %v logs, want 2", logger.LogHistory.Len())
+	}
+
+	os.Setenv(LOG_LEVEL_ENV, "invalid")
+	if logger = NewLogger(); logger.MinLevel != TRACE {
+		t.Errorf("invalid log level env should log every level, got %v", logger.MinLevel)
+	}
 }
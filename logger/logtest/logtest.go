@@ -0,0 +1,217 @@
+// Package logtest captures the logs of a logger.Logger so tests can assert what was logged.
+package logtest
+
+import (
+	"fmt"
+	"go-micro/logger"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+// Recorder captures every log written by its Logger. The Logger streams each log to the recorder as
+// soon as it is logged at every level, and keeps the redaction, fields and IDs of a regular logger.
+// Loggers derived from it, e.g. the request-scoped loggers of the service endpoints, share the
+// recorder. A Recorder is safe for concurrent use.
+type Recorder struct {
+	Logger logger.Logger
+	sink   *logger.MemorySink
+}
+
+// Matcher selects the captured logs. A zero Matcher matches every log.
+type Matcher struct {
+	desc  string
+	match func(lg logger.Log) bool
+}
+
+// New will create a recorder with a new capturing logger.
+func New() *Recorder {
+	return Capture(logger.NewLogger())
+}
+
+// Capture will create a recorder capturing the logs of a copy of lgr, which keeps its configuration
+// but logs every level in STREAM_MODE to the recorder only. The Sampler and RateLimiter of lgr are
+// dropped so no log is left out of the recorder.
+func Capture(lgr logger.Logger) *Recorder {
+	sink := logger.NewMemorySink(logger.TRACE)
+	lgr = lgr.Derive()
+	lgr.MinLevel = logger.TRACE
+	lgr.Mode = logger.STREAM_MODE
+	lgr.Sinks = []logger.Sink{sink}
+	lgr.Sampler = nil
+	lgr.RateLimiter = nil
+	return &Recorder{
+		Logger: lgr,
+		sink:   sink,
+	}
+}
+
+// Logs will return a copy of the captured logs in the order they were logged.
+func (r *Recorder) Logs() []logger.Log {
+	return r.sink.Logs()
+}
+
+// Reset will remove all the captured logs.
+func (r *Recorder) Reset() {
+	r.sink.Reset()
+}
+
+// Find will return the captured logs matching all the matchers in order.
+func (r *Recorder) Find(matchers ...Matcher) []logger.Log {
+	m := All(matchers...)
+	found := []logger.Log{}
+	for _, lg := range r.Logs() {
+		if m.Matches(lg) {
+			found = append(found, lg)
+		}
+	}
+	return found
+}
+
+// FindFirst will return the first captured log matching all the matchers and whether there is one.
+func (r *Recorder) FindFirst(matchers ...Matcher) (logger.Log, bool) {
+	m := All(matchers...)
+	for _, lg := range r.Logs() {
+		if m.Matches(lg) {
+			return lg, true
+		}
+	}
+	return logger.Log{}, false
+}
+
+// Count will return the number of captured logs matching all the matchers.
+func (r *Recorder) Count(matchers ...Matcher) int {
+	return len(r.Find(matchers...))
+}
+
+// AssertLogged will fail the test when no captured log matches all the matchers. It returns the first
+// matching log.
+func (r *Recorder) AssertLogged(t testing.TB, matchers ...Matcher) logger.Log {
+	t.Helper()
+	lg, ok := r.FindFirst(matchers...)
+	if !ok {
+		t.Errorf("no log %v, got logs:\n%v", All(matchers...), r.describeLogs())
+	}
+	return lg
+}
+
+// AssertNotLogged will fail the test when a captured log matches all the matchers.
+func (r *Recorder) AssertNotLogged(t testing.TB, matchers ...Matcher) {
+	t.Helper()
+	if lg, ok := r.FindFirst(matchers...); ok {
+		t.Errorf("unexpected log %v, got %v", All(matchers...), describeLog(lg))
+	}
+}
+
+// AssertOrder will fail the test unless the captured logs contain a log matching each matcher in the
+// given order. Other logs may come before, between and after them.
+func (r *Recorder) AssertOrder(t testing.TB, sequence ...Matcher) {
+	t.Helper()
+	logs := r.Logs()
+	next := 0
+	for _, m := range sequence {
+		for next < len(logs) && !m.Matches(logs[next]) {
+			next++
+		}
+		if next == len(logs) {
+			t.Errorf("no log %v after the previous logs of the sequence, got logs:\n%v", m, r.describeLogs())
+			return
+		}
+		next++
+	}
+}
+
+// describeLog will format a log for the failure messages.
+func describeLog(lg logger.Log) string {
+	return fmt.Sprintf("%v %v %q %v", lg.LogLevel, lg.ModuleName, lg.Text, lg.Data)
+}
+
+// describeLogs will format the captured logs one per line for the failure messages.
+func (r *Recorder) describeLogs() string {
+	lines := []string{}
+	for _, lg := range r.Logs() {
+		lines = append(lines, "\t"+describeLog(lg))
+	}
+	return strings.Join(lines, "\n")
+}
+
+// Matches will check if the log is selected by the matcher.
+func (m Matcher) Matches(lg logger.Log) bool {
+	return m.match == nil || m.match(lg)
+}
+
+// String will describe the logs selected by the matcher.
+func (m Matcher) String() string {
+	if m.desc == "" {
+		return "of any kind"
+	}
+	return m.desc
+}
+
+// Level will match the logs of the log level.
+func Level(logLvl logger.LogLevel) Matcher {
+	return Matcher{
+		desc:  "at level " + logLvl.String(),
+		match: func(lg logger.Log) bool { return lg.LogLevel == logLvl },
+	}
+}
+
+// Module will match the logs of a module, the package and function name like "servicehandler.NewS3Endpoint.func1".
+func Module(name string) Matcher {
+	return Matcher{
+		desc:  "of module " + name,
+		match: func(lg logger.Log) bool { return lg.ModuleName == name },
+	}
+}
+
+// Text will match the logs whose text contains substr.
+func Text(substr string) Matcher {
+	return Matcher{
+		desc:  fmt.Sprintf("with text containing %q", substr),
+		match: func(lg logger.Log) bool { return strings.Contains(lg.Text, substr) },
+	}
+}
+
+// DataKey will match the logs with the key in their data.
+func DataKey(key string) Matcher {
+	return Matcher{
+		desc: fmt.Sprintf("with data key %q", key),
+		match: func(lg logger.Log) bool {
+			_, ok := lg.Data[key]
+			return ok
+		},
+	}
+}
+
+// Data will match the logs with the value at the key of their data. The value is compared to the
+// flattened log data, so struct values must be given as maps.
+func Data(key string, value interface{}) Matcher {
+	return Matcher{
+		desc: fmt.Sprintf("with data %v=%v", key, value),
+		match: func(lg logger.Log) bool {
+			v, ok := lg.Data[key]
+			return ok && reflect.DeepEqual(v, value)
+		},
+	}
+}
+
+// All will match the logs matching all the matchers.
+func All(matchers ...Matcher) Matcher {
+	descs := []string{}
+	for _, m := range matchers {
+		if m.desc != "" {
+			descs = append(descs, m.desc)
+		}
+	}
+	return Matcher{
+		desc: strings.Join(descs, " and "),
+		match: func(lg logger.Log) bool {
+			for _, m := range matchers {
+				if !m.Matches(lg) {
+					return false
+				}
+			}
+			return true
+		},
+	}
+}
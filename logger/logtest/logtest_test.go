@@ -0,0 +1,164 @@
+package logtest
+
+import (
+	"errors"
+	"fmt"
+	"go-micro/logger"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+// recordingT records the failures of the assertions instead of failing the test
+type recordingT struct {
+	testing.TB
+	failures []string
+}
+
+func (rt *recordingT) Helper() {}
+
+func (rt *recordingT) Errorf(format string, args ...interface{}) {
+	rt.failures = append(rt.failures, fmt.Sprintf(format, args...))
+}
+
+// newFallbackRecorder will create a recorder with the logs of a handler falling back to a default
+func newFallbackRecorder() *Recorder {
+	rec := New()
+	rec.Logger.LogTxt(logger.INFO, "Fetching user settings")
+	rec.Logger.LogObj(logger.WARN, "Falling back to default settings",
+		map[string]interface{}{"userId": "1234", "reason": "timeout"}, "", false)
+	rec.Logger.LogFields(logger.ERROR, "Settings cache unavailable", logger.Err(errors.New("connection refused")))
+	rec.Logger.LogTxt(logger.INFO, "Returning settings")
+	return rec
+}
+
+var findTests = []struct {
+	testName  string
+	matchers  []Matcher
+	wantTexts []string
+}{
+	{"no matchers", nil, []string{"Fetching user settings", "Falling back to default settings",
+		"Settings cache unavailable", "Returning settings"}},
+	{"level", []Matcher{Level(logger.INFO)}, []string{"Fetching user settings", "Returning settings"}},
+	{"module", []Matcher{Module("logtest.newFallbackRecorder"), Level(logger.ERROR)},
+		[]string{"Settings cache unavailable"}},
+	{"other module", []Matcher{Module("servicehandler.NewServiceEndpoint")}, []string{}},
+	{"text", []Matcher{Text("settings")}, []string{"Fetching user settings", "Falling back to default settings",
+		"Returning settings"}},
+	{"data key", []Matcher{DataKey("error")}, []string{"Settings cache unavailable"}},
+	{"data value", []Matcher{Data("reason", "timeout")}, []string{"Falling back to default settings"}},
+	{"wrong data value", []Matcher{Data("reason", "not found")}, []string{}},
+	{"all", []Matcher{All(Level(logger.WARN), Text("Falling back")), DataKey("userId")},
+		[]string{"Falling back to default settings"}},
+}
+
+func TestFind(t *testing.T) {
+	rec := newFallbackRecorder()
+	for _, tt := range findTests {
+		t.Run(tt.testName, func(t *testing.T) {
+			texts := []string{}
+			for _, lg := range rec.Find(tt.matchers...) {
+				texts = append(texts, lg.Text)
+			}
+			if !reflect.DeepEqual(texts, tt.wantTexts) {
+				t.Errorf("found logs %v, want %v", texts, tt.wantTexts)
+			}
+			if count := rec.Count(tt.matchers...); count != len(tt.wantTexts) {
+				t.Errorf("count got %v, want %v", count, len(tt.wantTexts))
+			}
+			lg, ok := rec.FindFirst(tt.matchers...)
+			if ok != (len(tt.wantTexts) > 0) || (ok && lg.Text != tt.wantTexts[0]) {
+				t.Errorf("first log got %v %v, want %v", lg.Text, ok, tt.wantTexts)
+			}
+		})
+	}
+}
+
+var assertTests = []struct {
+	testName     string
+	assert       func(t testing.TB, rec *Recorder)
+	wantFailures []string
+}{
+	{"logged", func(t testing.TB, rec *Recorder) {
+		rec.AssertLogged(t, Level(logger.WARN), Text("Falling back"))
+	}, nil},
+	{"not logged", func(t testing.TB, rec *Recorder) {
+		rec.AssertLogged(t, Level(logger.WARN), Text("Returning"))
+	}, []string{`no log at level WARN and with text containing "Returning"`}},
+	{"absent", func(t testing.TB, rec *Recorder) {
+		rec.AssertNotLogged(t, Level(logger.FATAL))
+	}, nil},
+	{"present", func(t testing.TB, rec *Recorder) {
+		rec.AssertNotLogged(t, Level(logger.ERROR))
+	}, []string{`unexpected log at level ERROR, got ERROR logtest.newFallbackRecorder "Settings cache unavailable"`}},
+	{"in order", func(t testing.TB, rec *Recorder) {
+		rec.AssertOrder(t, Text("Fetching"), Level(logger.WARN), Text("Returning"))
+	}, nil},
+	{"out of order", func(t testing.TB, rec *Recorder) {
+		rec.AssertOrder(t, Level(logger.ERROR), Level(logger.WARN))
+	}, []string{"no log at level WARN after the previous logs of the sequence"}},
+	{"repeated", func(t testing.TB, rec *Recorder) {
+		rec.AssertOrder(t, Level(logger.WARN), Level(logger.WARN))
+	}, []string{"no log at level WARN after the previous logs of the sequence"}},
+}
+
+func TestAssertions(t *testing.T) {
+	rec := newFallbackRecorder()
+	for _, tt := range assertTests {
+		t.Run(tt.testName, func(t *testing.T) {
+			rt := &recordingT{TB: t}
+			tt.assert(rt, rec)
+			if len(rt.failures) != len(tt.wantFailures) {
+				t.Fatalf("failures got %v, want %v", rt.failures, tt.wantFailures)
+			}
+			for i, want := range tt.wantFailures {
+				if !strings.HasPrefix(rt.failures[i], want) {
+					t.Errorf("failure got %v, want prefix %v", rt.failures[i], want)
+				}
+			}
+		})
+	}
+}
+
+func TestCapture(t *testing.T) {
+	base := logger.NewLogger()
+	base.MinLevel = logger.ERROR
+	base.RequestID = "request-1"
+	base.Fields = map[string]interface{}{"service": "users"}
+	rec := Capture(base)
+
+	derived := rec.Logger.Derive()
+	derived.LogTxt(logger.DEBUG, "Invited juan@example.com")
+
+	lg := rec.AssertLogged(t, Level(logger.DEBUG))
+	if lg.RequestID != "request-1" || lg.Data["service"] != "users" || lg.Text != "Invited [REDACTED]" {
+		t.Errorf("captured log got %v %v %v, want the configuration of the base logger", lg.RequestID, lg.Data, lg.Text)
+	}
+	if base.LogHistory.Len() != 0 {
+		t.Errorf("base logger history got %v logs, want 0", base.LogHistory.Len())
+	}
+
+	rec.Reset()
+	if logs := rec.Logs(); len(logs) != 0 {
+		t.Errorf("logs after reset got %v, want none", logs)
+	}
+}
+
+func TestCaptureSampledLogger(t *testing.T) {
+	base := logger.NewLogger()
+	base.CorrelationID = "correlation-1"
+	base.Sampler = logger.NewSampler(0)
+	base.RateLimiter = logger.NewRateLimiter(1, time.Minute)
+	rec := Capture(base)
+
+	for i := 0; i < 3; i++ {
+		rec.Logger.LogTxt(logger.INFO, "Retrying")
+	}
+	if got := rec.Count(Text("Retrying")); got != 3 {
+		t.Errorf("captured logs of a sampled and rate limited logger got %v, want 3", got)
+	}
+	if base.Sampler == nil || base.RateLimiter == nil {
+		t.Errorf("capture changed the configuration of the base logger")
+	}
+}
@@ -0,0 +1,88 @@
+package logger
+
+import "sort"
+
+// LogMode selects when the logs of a logger are written to its sinks.
+type LogMode int
+
+const (
+	// BUFFER_MODE keeps the logs in the log history until Flush
+	BUFFER_MODE LogMode = iota
+	// STREAM_MODE writes each log as soon as it is logged
+	STREAM_MODE
+	// FLUSH_ON_ERROR_MODE writes INFO and above as soon as they are logged and keeps
+	// DEBUG and TRACE logs until Flush, writing them only when the invocation failed
+	FLUSH_ON_ERROR_MODE
+)
+
+// isBuffered will check if a log of the log level is held until Flush in the mode.
+func (mode LogMode) isBuffered(logLvl LogLevel) bool {
+	switch mode {
+	case STREAM_MODE:
+		return false
+	case FLUSH_ON_ERROR_MODE:
+		return logLvl < INFO
+	default:
+		return true
+	}
+}
+
+// record will drop the log when it is not sampled or over the rate limit, and append it otherwise.
+// The logs over the rate limit are counted as suppressed by the log history of the logger.
+func (lgr Logger) record(lg Log) {
+	if !lgr.Sampler.Keep(lg) {
+		return
+	}
+	if !lgr.RateLimiter.Allow(lg.Text) {
+		lgr.LogHistory.Suppress(lg.Text)
+		return
+	}
+	lgr.append(lg)
+}
+
+// append will redact the log and append it to the log history, writing it right away unless the mode buffers it.
+func (lgr Logger) append(lg Log) {
+	lg = lgr.Redactor.Redact(lg)
+	lgr.LogHistory.Append(lg)
+	if !lgr.Mode.isBuffered(lg.LogLevel) {
+		lgr.writeLog(lg)
+	}
+}
+
+// Flush will write the buffered logs at the end of an invocation in historical order.
+// In FLUSH_ON_ERROR_MODE the buffered logs are only written when failed is true.
+// The logs of the logger suppressed by the rate limiter since the last flush are summarized in a WARN log first.
+func (lgr Logger) Flush(failed bool) {
+	if suppressed := lgr.LogHistory.TakeSuppressed(); len(suppressed) > 0 && lgr.Enabled(WARN) {
+		lgr.append(lgr.newLog(1, WARN, SUPPRESSED_LOGS_TEXT, suppressedLogData(suppressed)))
+	}
+	switch lgr.Mode {
+	case STREAM_MODE:
+		return
+	case FLUSH_ON_ERROR_MODE:
+		if !failed {
+			return
+		}
+	}
+	lgr.LogHistory.Backward(func(lg Log) bool {
+		if lgr.Mode.isBuffered(lg.LogLevel) {
+			lgr.writeLog(lg)
+		}
+		return true
+	})
+}
+
+// suppressedLogData will list the suppressed counts by message in order as the data of the summary log,
+// with the messages as values so they are redacted like log texts.
+func suppressedLogData(suppressed map[string]int) map[string]interface{} {
+	messages := make([]string, 0, len(suppressed))
+	for msg := range suppressed {
+		messages = append(messages, msg)
+	}
+	sort.Strings(messages)
+	counts := make([]interface{}, 0, len(messages))
+	for _, msg := range messages {
+		counts = append(counts, map[string]interface{}{"message": msg, "count": suppressed[msg]})
+	}
+	return map[string]interface{}{"suppressed": counts}
+}
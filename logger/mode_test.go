@@ -0,0 +1,54 @@
+package logger
+
+import (
+	"reflect"
+	"testing"
+)
+
+var logModeTests = []struct {
+	testName       string
+	mode           LogMode
+	failed         bool
+	wantBeforeEnd  []string
+	wantAfterFlush []string
+}{
+	{"buffer mode", BUFFER_MODE, false, []string{}, []string{"debug", "info", "error"}},
+	{"stream mode", STREAM_MODE, true, []string{"debug", "info", "error"}, []string{"debug", "info", "error"}},
+	{"flush on error mode success", FLUSH_ON_ERROR_MODE, false, []string{"info", "error"}, []string{"info", "error"}},
+	{"flush on error mode failure", FLUSH_ON_ERROR_MODE, true, []string{"info", "error"}, []string{"info", "error", "debug"}},
+}
+
+// sinkTexts will return the texts of the logs written to the memory sink
+func sinkTexts(sink *MemorySink) []string {
+	texts := []string{}
+	for _, lg := range sink.Logs() {
+		texts = append(texts, lg.Text)
+	}
+	return texts
+}
+
+func TestLogModes(t *testing.T) {
+	for _, tt := range logModeTests {
+		t.Run(tt.testName, func(t *testing.T) {
+			sink := NewMemorySink(TRACE)
+			lgr := NewLogger()
+			lgr.MinLevel = TRACE
+			lgr.Mode = tt.mode
+			lgr.Sinks = []Sink{sink}
+
+			lgr.LogTxt(DEBUG, "debug")
+			lgr.LogObj(INFO, "info", map[string]interface{}{}, "", false)
+			lgr.LogTxt(ERROR, "error")
+			if got := sinkTexts(sink); !reflect.DeepEqual(got, tt.wantBeforeEnd) {
+				t.Errorf("logs written before flush got %v, want %v", got, tt.wantBeforeEnd)
+			}
+			lgr.Flush(tt.failed)
+			if got := sinkTexts(sink); !reflect.DeepEqual(got, tt.wantAfterFlush) {
+				t.Errorf("logs written after flush got %v, want %v", got, tt.wantAfterFlush)
+			}
+			if lgr.LogHistory.Len() != 3 {
+				t.Errorf("log history got %v logs, want 3", lgr.LogHistory.Len())
+			}
+		})
+	}
+}
@@ -0,0 +1,83 @@
+package logger
+
+import (
+	"sync"
+	"time"
+)
+
+// MAX_RATE_LIMITED_MESSAGES is the number of distinct messages a RateLimiter tracks. Messages beyond it
+// are not rate limited until the windows of the tracked messages expire.
+const MAX_RATE_LIMITED_MESSAGES = 10000
+
+// SUPPRESSED_LOGS_TEXT is the text of the summary log of the suppressed logs
+const SUPPRESSED_LOGS_TEXT = "Suppressed rate limited logs"
+
+// clock is the clock of the rate limit windows that can be mocked in testing
+var clock = time.Now
+
+// rateWindow counts the logs of a message in the current window
+type rateWindow struct {
+	start time.Time
+	count int
+}
+
+// RateLimiter limits each message to Burst logs per Interval. A RateLimiter is shared by the derived
+// loggers, so the limit applies to the logs of all the invocations together, and is safe for concurrent
+// use. The suppressed logs are counted by the LogHistory of the logger that logged them, so each
+// invocation summarizes its own suppressed logs when it is flushed.
+type RateLimiter struct {
+	mu       sync.Mutex
+	Burst    int
+	Interval time.Duration
+	windows  map[string]*rateWindow
+}
+
+// NewRateLimiter will create a rate limiter allowing burst logs of the same message per interval.
+func NewRateLimiter(burst int, interval time.Duration) *RateLimiter {
+	return &RateLimiter{
+		Burst:    burst,
+		Interval: interval,
+		windows:  map[string]*rateWindow{},
+	}
+}
+
+// pruneWindows will remove the expired windows.
+func (rl *RateLimiter) pruneWindows(t time.Time) {
+	for msg, w := range rl.windows {
+		if t.Sub(w.start) >= rl.Interval {
+			delete(rl.windows, msg)
+		}
+	}
+}
+
+// Allow will check if a log of the message is within the limit. A nil rate limiter allows every log.
+func (rl *RateLimiter) Allow(msg string) bool {
+	if rl == nil {
+		return true
+	}
+	t := clock()
+	rl.mu.Lock()
+	defer rl.mu.Unlock()
+	if rl.windows == nil {
+		rl.windows = map[string]*rateWindow{}
+	}
+	w, ok := rl.windows[msg]
+	if !ok {
+		if len(rl.windows) >= MAX_RATE_LIMITED_MESSAGES {
+			rl.pruneWindows(t)
+			if len(rl.windows) >= MAX_RATE_LIMITED_MESSAGES {
+				return true
+			}
+		}
+		w = &rateWindow{start: t}
+		rl.windows[msg] = w
+	}
+	if t.Sub(w.start) >= rl.Interval {
+		w.start, w.count = t, 0
+	}
+	if w.count >= rl.Burst {
+		return false
+	}
+	w.count++
+	return true
+}
@@ -0,0 +1,119 @@
+package logger
+
+import (
+	"fmt"
+	"reflect"
+	"testing"
+	"time"
+)
+
+// mockClock will set the clock of the rate limit windows and return the function restoring it
+func mockClock(t *time.Time) func() {
+	clock = func() time.Time { return *t }
+	return func() { clock = time.Now }
+}
+
+func TestRateLimiterAllow(t *testing.T) {
+	now := time.Date(2021, 5, 1, 10, 30, 0, 0, time.UTC)
+	defer mockClock(&now)()
+	rl := NewRateLimiter(2, time.Second)
+
+	got := []bool{}
+	for i := 0; i < 4; i++ {
+		got = append(got, rl.Allow("Fetching user"))
+	}
+	got = append(got, rl.Allow("Saving user"))
+	now = now.Add(time.Second)
+	got = append(got, rl.Allow("Fetching user"))
+
+	want := []bool{true, true, false, false, true, true}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("rate limiter allowed %v, want %v", got, want)
+	}
+}
+
+func TestRateLimiterMaxMessages(t *testing.T) {
+	now := time.Date(2021, 5, 1, 10, 30, 0, 0, time.UTC)
+	defer mockClock(&now)()
+	rl := NewRateLimiter(0, time.Second)
+	for i := 0; i < MAX_RATE_LIMITED_MESSAGES; i++ {
+		rl.Allow(fmt.Sprintf("message %d", i))
+	}
+	if !rl.Allow("untracked message") {
+		t.Errorf("message beyond the tracked messages was rate limited")
+	}
+	now = now.Add(time.Second)
+	if rl.Allow("tracked message") || len(rl.windows) != 1 {
+		t.Errorf("expired windows not pruned, tracking %v messages", len(rl.windows))
+	}
+}
+
+func TestNilRateLimiter(t *testing.T) {
+	var rl *RateLimiter
+	if !rl.Allow("message") {
+		t.Errorf("nil rate limiter limited a log")
+	}
+}
+
+func TestLoggerRateLimit(t *testing.T) {
+	now := time.Date(2021, 5, 1, 10, 30, 0, 0, time.UTC)
+	defer mockClock(&now)()
+	sink := NewMemorySink(TRACE)
+	lgr := NewLogger()
+	lgr.Mode = STREAM_MODE
+	lgr.Sinks = []Sink{sink}
+	lgr.RateLimiter = NewRateLimiter(1, time.Minute)
+
+	for i := 0; i < 3; i++ {
+		lgr.LogTxt(INFO, "Retrying juan@email.com")
+	}
+	lgr.LogTxt(INFO, "Done")
+	lgr.Flush(false)
+	lgr.Flush(false)
+
+	logs := sink.Logs()
+	if got, want := sinkTexts(sink), []string{"Retrying [REDACTED]", "Done", SUPPRESSED_LOGS_TEXT}; !reflect.DeepEqual(got, want) {
+		t.Fatalf("rate limited logs got %v, want %v", got, want)
+	}
+	summary := logs[2]
+	wantData := map[string]interface{}{
+		"suppressed": []interface{}{map[string]interface{}{"message": "Retrying [REDACTED]", "count": 2}},
+	}
+	if summary.LogLevel != WARN || !reflect.DeepEqual(summary.Data, wantData) {
+		t.Errorf("suppressed summary got %v %v, want %v", summary.LogLevel, summary.Data, wantData)
+	}
+}
+
+func TestDerivedLoggerRateLimit(t *testing.T) {
+	now := time.Date(2021, 5, 1, 10, 30, 0, 0, time.UTC)
+	defer mockClock(&now)()
+	sink := NewMemorySink(TRACE)
+	base := NewLogger()
+	base.Mode = STREAM_MODE
+	base.Sinks = []Sink{sink}
+	base.RateLimiter = NewRateLimiter(1, time.Minute)
+
+	// The limit is shared, the suppressed logs are summarized by the invocation that logged them
+	first, second := base.Derive(), base.Derive()
+	first.LogTxt(INFO, "Retrying")
+	first.LogTxt(INFO, "Retrying")
+	second.LogTxt(INFO, "Retrying")
+	second.LogTxt(INFO, "Retrying")
+	second.LogTxt(INFO, "Retrying")
+	second.Flush(false)
+	first.Flush(false)
+
+	summaries := []interface{}{}
+	for _, lg := range sink.Logs() {
+		if lg.Text == SUPPRESSED_LOGS_TEXT {
+			summaries = append(summaries, lg.Data["suppressed"])
+		}
+	}
+	want := []interface{}{
+		[]interface{}{map[string]interface{}{"message": "Retrying", "count": 3}},
+		[]interface{}{map[string]interface{}{"message": "Retrying", "count": 1}},
+	}
+	if !reflect.DeepEqual(summaries, want) {
+		t.Errorf("suppressed summaries got %v, want %v", summaries, want)
+	}
+}
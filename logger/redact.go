@@ -0,0 +1,247 @@
+package logger
+
+import (
+	"regexp"
+	"sort"
+	"strings"
+)
+
+// REDACTED is the replacement of the masked values
+const REDACTED = "[REDACTED]"
+
+// DefaultRedactedKeys are the keys masked by the default redactor
+var DefaultRedactedKeys = []string{
+	"password",
+	"secret",
+	"token",
+	"accessToken",
+	"refreshToken",
+	"idToken",
+	"apiKey",
+	"authorization",
+	"cookie",
+}
+
+// Masker masks the sensitive parts of a string value.
+type Masker func(s string) string
+
+var (
+	emailPattern      = regexp.MustCompile(`[A-Za-z0-9._%+\-]+@[A-Za-z0-9.\-]+\.[A-Za-z]{2,}`)
+	cardNumberPattern = regexp.MustCompile(`\b\d(?:[ \-]?\d){12,18}\b`)
+)
+
+// Redactor masks the values of the configured keys at any nesting depth of the log data, and
+// the parts of the log text and string values matched by its maskers. Keys are case-insensitive.
+// A Redactor is immutable and safe for concurrent use.
+type Redactor struct {
+	keys       map[string]bool
+	keyPattern *regexp.Regexp
+	maskers    []Masker
+}
+
+// MIN_CARD_NUMBER_DIGITS is the number of digits of the shortest card numbers
+const MIN_CARD_NUMBER_DIGITS = 13
+
+// MaskEmails will mask the email addresses in s.
+func MaskEmails(s string) string {
+	if strings.IndexByte(s, '@') < 0 {
+		return s
+	}
+	return emailPattern.ReplaceAllString(s, REDACTED)
+}
+
+// hasDigits will check if s contains at least n digits.
+func hasDigits(s string, n int) bool {
+	for i := 0; i < len(s) && n > 0; i++ {
+		if s[i] >= '0' && s[i] <= '9' {
+			n--
+		}
+	}
+	return n == 0
+}
+
+// MaskCardNumbers will mask the digit sequences in s that are valid card numbers.
+func MaskCardNumbers(s string) string {
+	if !hasDigits(s, MIN_CARD_NUMBER_DIGITS) {
+		return s
+	}
+	return cardNumberPattern.ReplaceAllStringFunc(s, func(match string) string {
+		if isLuhnValid(match) {
+			return REDACTED
+		}
+		return match
+	})
+}
+
+// PatternMasker will create a masker replacing the matches of the regular expression.
+func PatternMasker(re *regexp.Regexp) Masker {
+	return func(s string) string {
+		return re.ReplaceAllString(s, REDACTED)
+	}
+}
+
+// isLuhnValid will check the Luhn checksum of the digits in s.
+func isLuhnValid(s string) bool {
+	sum, double := 0, false
+	for i := len(s) - 1; i >= 0; i-- {
+		if s[i] < '0' || s[i] > '9' {
+			continue
+		}
+		d := int(s[i] - '0')
+		if double {
+			if d *= 2; d > 9 {
+				d -= 9
+			}
+		}
+		sum += d
+		double = !double
+	}
+	return sum%10 == 0
+}
+
+// NewRedactor will create a redactor masking the keys and the matches of the maskers.
+func NewRedactor(keys []string, maskers ...Masker) *Redactor {
+	rd := &Redactor{
+		keys:    map[string]bool{},
+		maskers: maskers,
+	}
+	for _, k := range keys {
+		rd.keys[strings.ToLower(k)] = true
+	}
+	rd.compileKeyPattern()
+	return rd
+}
+
+// DefaultRedactor will create a redactor masking the DefaultRedactedKeys, emails and card numbers.
+// Card numbers are matched by their Luhn checksum, so about 1 in 10 of other 13 to 19 digit sequences
+// like order or account ids are masked as well. Use NewRedactor without MaskCardNumbers when logged
+// ids must stay readable.
+func DefaultRedactor() *Redactor {
+	return NewRedactor(DefaultRedactedKeys, MaskEmails, MaskCardNumbers)
+}
+
+// defaultRedactor is the redactor shared by new loggers
+var defaultRedactor = DefaultRedactor()
+
+// compileKeyPattern will compile the pattern masking the values of the keys in JSON text.
+func (rd *Redactor) compileKeyPattern() {
+	if len(rd.keys) == 0 {
+		rd.keyPattern = nil
+		return
+	}
+	quoted := make([]string, 0, len(rd.keys))
+	for k := range rd.keys {
+		quoted = append(quoted, regexp.QuoteMeta(k))
+	}
+	sort.Strings(quoted)
+	rd.keyPattern = regexp.MustCompile(
+		`(?i)("(?:` + strings.Join(quoted, "|") + `)"\s*:\s*)("(?:[^"\\]|\\.)*"|[^,}\]\s]+)`,
+	)
+}
+
+// WithKeys will create a redactor masking the keys in addition to the keys of rd.
+// A nil redactor creates a redactor masking only the keys.
+func (rd *Redactor) WithKeys(keys ...string) *Redactor {
+	if rd == nil {
+		return NewRedactor(keys)
+	}
+	if len(keys) == 0 {
+		return rd
+	}
+	merged := &Redactor{
+		keys:    make(map[string]bool, len(rd.keys)+len(keys)),
+		maskers: rd.maskers,
+	}
+	for k := range rd.keys {
+		merged.keys[k] = true
+	}
+	for _, k := range keys {
+		merged.keys[strings.ToLower(k)] = true
+	}
+	merged.compileKeyPattern()
+	return merged
+}
+
+// IsRedactedKey will check if the values of the key are masked.
+func (rd *Redactor) IsRedactedKey(key string) bool {
+	return rd.keys[strings.ToLower(key)]
+}
+
+// redactString will mask the values of the keys in JSON text and the matches of the maskers in s.
+func (rd *Redactor) redactString(s string) string {
+	// The keys are only matched in JSON text, which quotes them
+	if rd.keyPattern != nil && strings.IndexByte(s, '"') >= 0 {
+		s = rd.keyPattern.ReplaceAllString(s, `${1}"`+REDACTED+`"`)
+	}
+	for _, mask := range rd.maskers {
+		s = mask(s)
+	}
+	return s
+}
+
+// redactValue will return a copy of the value with the sensitive values masked.
+func (rd *Redactor) redactValue(v interface{}) interface{} {
+	switch val := v.(type) {
+	case string:
+		return rd.redactString(val)
+	case map[string]interface{}:
+		return rd.redactMap(val)
+	case map[string]string:
+		redacted := make(map[string]interface{}, len(val))
+		for k, s := range val {
+			if rd.IsRedactedKey(k) {
+				redacted[k] = REDACTED
+			} else {
+				redacted[k] = rd.redactString(s)
+			}
+		}
+		return redacted
+	case []interface{}:
+		redacted := make([]interface{}, len(val))
+		for i := range val {
+			redacted[i] = rd.redactValue(val[i])
+		}
+		return redacted
+	case []string:
+		redacted := make([]interface{}, len(val))
+		for i := range val {
+			redacted[i] = rd.redactString(val[i])
+		}
+		return redacted
+	}
+	return v
+}
+
+// redactMap will return a copy of the map with the sensitive values masked at any depth.
+func (rd *Redactor) redactMap(data map[string]interface{}) map[string]interface{} {
+	if data == nil {
+		return nil
+	}
+	redacted := make(map[string]interface{}, len(data))
+	for k, v := range data {
+		if rd.IsRedactedKey(k) {
+			redacted[k] = REDACTED
+			continue
+		}
+		redacted[k] = rd.redactValue(v)
+	}
+	return redacted
+}
+
+// Redact will return a copy of the log with the sensitive values of its text, data and errors masked.
+func (rd *Redactor) Redact(lg Log) Log {
+	if rd == nil {
+		return lg
+	}
+	lg.Text = rd.redactString(lg.Text)
+	lg.Data = rd.redactMap(lg.Data)
+	if lg.Errors != nil {
+		errorChain := make([]ErrorInfo, len(lg.Errors))
+		for i, e := range lg.Errors {
+			e.Message = rd.redactString(e.Message)
+			errorChain[i] = e
+		}
+		lg.Errors = errorChain
+	}
+	return lg
+}
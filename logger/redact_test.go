@@ -0,0 +1,151 @@
+package logger
+
+import (
+	"reflect"
+	"testing"
+)
+
+var redactTests = []struct {
+	testName string
+	redactor *Redactor
+	log      Log
+	wantText string
+	wantData map[string]interface{}
+}{
+	{
+		"default keys at any depth",
+		DefaultRedactor(),
+		Log{Data: map[string]interface{}{
+			"username": "juan",
+			"Password": "hunter2",
+			"session":  map[string]interface{}{"accessToken": "abc", "expires": 3600},
+			"devices":  []interface{}{map[string]interface{}{"token": "def", "name": "phone"}},
+			"headers":  map[string]string{"Authorization": "Bearer xyz", "Accept": "*/*"},
+		}},
+		"",
+		map[string]interface{}{
+			"username": "juan",
+			"Password": REDACTED,
+			"session":  map[string]interface{}{"accessToken": REDACTED, "expires": 3600},
+			"devices":  []interface{}{map[string]interface{}{"token": REDACTED, "name": "phone"}},
+			"headers":  map[string]interface{}{"Authorization": REDACTED, "Accept": "*/*"},
+		},
+	},
+	{
+		"keys in json text",
+		DefaultRedactor(),
+		Log{Text: `Return Body: {"user": "juan", "password": "hun\"ter2", "Token" : 1234}`},
+		`Return Body: {"user": "juan", "password": "[REDACTED]", "Token" : "[REDACTED]"}`,
+		nil,
+	},
+	{
+		"emails",
+		DefaultRedactor(),
+		Log{
+			Text: "Sending mail to juan.dela-cruz@email.com",
+			Data: map[string]interface{}{"emails": []string{"juan@email.com", "not an email"}},
+		},
+		"Sending mail to [REDACTED]",
+		map[string]interface{}{"emails": []interface{}{REDACTED, "not an email"}},
+	},
+	{
+		"card numbers",
+		DefaultRedactor(),
+		Log{
+			Text: "Charging 4111 1111 1111 1111 for order 1234567890123",
+			Data: map[string]interface{}{"card": "5500-0000-0000-0004", "amount": 100.5},
+		},
+		"Charging [REDACTED] for order 1234567890123",
+		map[string]interface{}{"card": REDACTED, "amount": 100.5},
+	},
+	{
+		"configured keys without maskers",
+		NewRedactor([]string{"ssn"}).WithKeys("dateOfBirth"),
+		Log{
+			Text: "juan@email.com",
+			Data: map[string]interface{}{"ssn": "123-45-6789", "dateofbirth": "2000-01-01", "password": "x"},
+		},
+		"juan@email.com",
+		map[string]interface{}{"ssn": REDACTED, "dateofbirth": REDACTED, "password": "x"},
+	},
+	{
+		"nil redactor",
+		nil,
+		Log{Text: "juan@email.com", Data: map[string]interface{}{"password": "x"}},
+		"juan@email.com",
+		map[string]interface{}{"password": "x"},
+	},
+}
+
+func TestRedact(t *testing.T) {
+	for _, tt := range redactTests {
+		t.Run(tt.testName, func(t *testing.T) {
+			got := tt.redactor.Redact(tt.log)
+			if got.Text != tt.wantText {
+				t.Errorf("redacted text got %v, want %v", got.Text, tt.wantText)
+			}
+			if !reflect.DeepEqual(got.Data, tt.wantData) {
+				t.Errorf("redacted data got %v, want %v", got.Data, tt.wantData)
+			}
+		})
+	}
+}
+
+func TestRedactDoesNotModifyData(t *testing.T) {
+	data := map[string]interface{}{"password": "hunter2", "nested": map[string]interface{}{"token": "abc"}}
+	DefaultRedactor().Redact(Log{Data: data})
+	if data["password"] != "hunter2" || data["nested"].(map[string]interface{})["token"] != "abc" {
+		t.Errorf("redaction modified the logged data")
+	}
+}
+
+func TestLoggerRedaction(t *testing.T) {
+	sink := NewMemorySink(TRACE)
+	lgr := NewLogger()
+	lgr.Mode = STREAM_MODE
+	lgr.Sinks = []Sink{sink}
+	lgr.LogObj(INFO, "Creating user juan@email.com", map[string]interface{}{"password": "hunter2"}, "", false)
+
+	for _, lg := range append(sink.Logs(), lgr.LogHistory.Logs()...) {
+		if lg.Text != "Creating user [REDACTED]" || lg.Data["password"] != REDACTED {
+			t.Errorf("log not redacted before reaching the sinks and history: %v", lg)
+		}
+	}
+}
+
+func TestMaskCardNumbersFalsePositive(t *testing.T) {
+	// A Luhn-valid id is indistinguishable from a card number
+	if got := MaskCardNumbers("order 1234567890128"); got != "order "+REDACTED {
+		t.Errorf("Luhn-valid id masked got %v", got)
+	}
+	if got := NewRedactor(DefaultRedactedKeys, MaskEmails).Redact(Log{Text: "order 1234567890128"}).Text; got != "order 1234567890128" {
+		t.Errorf("redactor without card masking got %v", got)
+	}
+}
+
+var benchmarkRedactLog = Log{
+	Text: "Creating new HTTP Response",
+	Data: map[string]interface{}{
+		"statusCode": 200,
+		"returnBody": `{"message": "User created", "userId": "user-1234"}`,
+		"headers":    map[string]string{"Content-Type": "application/json", "Authorization": "Bearer xyz"},
+		"path":       "/user/1234",
+	},
+}
+
+func BenchmarkRedact(b *testing.B) {
+	rd := DefaultRedactor()
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		rd.Redact(benchmarkRedactLog)
+	}
+}
+
+func BenchmarkRedactPlainText(b *testing.B) {
+	rd := DefaultRedactor()
+	lg := Log{Text: "Processing Stream Record <49590338271490256608559692538361571095921575989136588898>.."}
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		rd.Redact(lg)
+	}
+}
@@ -0,0 +1,54 @@
+package logger
+
+import (
+	"hash/fnv"
+	"math"
+)
+
+// Sampler keeps all the logs of a fraction of the requests and drops the others, except the logs at or
+// above KeepLevel. Requests are sampled deterministically by the hash of their correlation id, so every
+// service logging a request makes the same decision. Logs without a correlation id are always kept.
+// A zero KeepLevel keeps the ERROR and FATAL logs like NewSampler, so a Sampler literal with only a Rate
+// still samples the requests.
+type Sampler struct {
+	Rate      float64
+	KeepLevel LogLevel
+}
+
+// NewSampler will create a sampler keeping the logs of the rate fraction of the requests, e.g. 0.1 for
+// 10%, and every ERROR and FATAL log.
+func NewSampler(rate float64) *Sampler {
+	return &Sampler{
+		Rate:      rate,
+		KeepLevel: ERROR,
+	}
+}
+
+// Sampled will check if the logs of the request with the correlation id are kept.
+func (s *Sampler) Sampled(correlationID string) bool {
+	if s == nil || correlationID == "" || s.Rate >= 1 {
+		return true
+	}
+	if s.Rate <= 0 {
+		return false
+	}
+	h := fnv.New64a()
+	h.Write([]byte(correlationID))
+	return float64(h.Sum64()) < s.Rate*math.MaxUint64
+}
+
+// keepLevel will return the level from which the logs are always kept, ERROR when KeepLevel is not set.
+func (s *Sampler) keepLevel() LogLevel {
+	if s.KeepLevel == TRACE {
+		return ERROR
+	}
+	return s.KeepLevel
+}
+
+// Keep will check if the log is kept by the sampler. A nil sampler keeps every log.
+func (s *Sampler) Keep(lg Log) bool {
+	if s == nil || lg.LogLevel >= s.keepLevel() {
+		return true
+	}
+	return s.Sampled(lg.CorrelationID)
+}
@@ -0,0 +1,81 @@
+package logger
+
+import (
+	"fmt"
+	"reflect"
+	"testing"
+)
+
+var samplerKeepTests = []struct {
+	testName string
+	sampler  *Sampler
+	log      Log
+	want     bool
+}{
+	{"nil sampler", nil, Log{LogLevel: INFO, CorrelationID: "correlation-1"}, true},
+	{"rate zero info", NewSampler(0), Log{LogLevel: INFO, CorrelationID: "correlation-1"}, false},
+	{"rate zero error", NewSampler(0), Log{LogLevel: ERROR, CorrelationID: "correlation-1"}, true},
+	{"rate zero fatal", NewSampler(0), Log{LogLevel: FATAL, CorrelationID: "correlation-1"}, true},
+	{"rate one", NewSampler(1), Log{LogLevel: DEBUG, CorrelationID: "correlation-1"}, true},
+	{"no correlation id", NewSampler(0), Log{LogLevel: INFO}, true},
+	{"custom keep level", &Sampler{Rate: 0, KeepLevel: WARN}, Log{LogLevel: WARN, CorrelationID: "correlation-1"}, true},
+	{"zero keep level info", &Sampler{Rate: 0}, Log{LogLevel: INFO, CorrelationID: "correlation-1"}, false},
+	{"zero keep level error", &Sampler{Rate: 0}, Log{LogLevel: ERROR, CorrelationID: "correlation-1"}, true},
+}
+
+func TestSamplerKeep(t *testing.T) {
+	for _, tt := range samplerKeepTests {
+		t.Run(tt.testName, func(t *testing.T) {
+			if got := tt.sampler.Keep(tt.log); got != tt.want {
+				t.Errorf("sampler keep got %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestSamplerRate(t *testing.T) {
+	sampler := NewSampler(0.25)
+	sampled := 0
+	for i := 0; i < 10000; i++ {
+		correlationID := fmt.Sprintf("correlation-%d", i)
+		if sampler.Sampled(correlationID) {
+			sampled++
+		}
+		if sampler.Sampled(correlationID) != sampler.Sampled(correlationID) {
+			t.Fatalf("sampling of %v is not deterministic", correlationID)
+		}
+	}
+	if sampled < 2250 || sampled > 2750 {
+		t.Errorf("sampled %v of 10000 requests, want about 2500", sampled)
+	}
+}
+
+func TestLoggerSampling(t *testing.T) {
+	sampler := NewSampler(0.5)
+	sampledID, unsampledID := "", ""
+	for i := 0; sampledID == "" || unsampledID == ""; i++ {
+		correlationID := fmt.Sprintf("correlation-%d", i)
+		if sampler.Sampled(correlationID) {
+			sampledID = correlationID
+		} else {
+			unsampledID = correlationID
+		}
+	}
+
+	sink := NewMemorySink(TRACE)
+	lgr := NewLogger()
+	lgr.Mode = STREAM_MODE
+	lgr.Sinks = []Sink{sink}
+	lgr.Sampler = sampler
+	for _, correlationID := range []string{sampledID, unsampledID} {
+		reqLgr := lgr.Derive()
+		reqLgr.CorrelationID = correlationID
+		reqLgr.LogTxt(INFO, "info "+correlationID)
+		reqLgr.LogTxt(ERROR, "error "+correlationID)
+	}
+
+	want := []string{"info " + sampledID, "error " + sampledID, "error " + unsampledID}
+	if got := sinkTexts(sink); !reflect.DeepEqual(got, want) {
+		t.Errorf("sampled logs got %v, want %v", got, want)
+	}
+}
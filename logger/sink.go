@@ -0,0 +1,232 @@
+package logger
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"sync"
+)
+
+// Formatter formats a log into a single line of output.
+type Formatter func(lg Log) string
+
+// Built-in formatters of the text, JSON and console log formats. The colors of the ConsoleFormatter
+// are disabled by the NO_COLOR environment variable.
+var (
+	TextFormatter    Formatter = formatText
+	JSONFormatter    Formatter = formatJSON
+	ConsoleFormatter Formatter = NewConsoleFormatter(os.Getenv(NO_COLOR_ENV) == "")
+)
+
+// Sink is a destination of the displayed logs. Sinks are shared by derived loggers
+// and must be safe for concurrent use.
+type Sink interface {
+	WriteLog(lg Log) error
+}
+
+// WriterSink writes the logs at or above MinLevel to an io.Writer using its Formatter.
+type WriterSink struct {
+	mu        sync.Mutex
+	Writer    io.Writer
+	MinLevel  LogLevel
+	Formatter Formatter
+}
+
+// FileSink writes the logs at or above MinLevel to a local file using its Formatter.
+// The file is rotated once it reaches MaxBytes, keeping MaxBackups rotated files (path.1, path.2, ..).
+type FileSink struct {
+	mu         sync.Mutex
+	Path       string
+	MaxBytes   int64
+	MaxBackups int
+	MinLevel   LogLevel
+	Formatter  Formatter
+	file       *os.File
+	size       int64
+}
+
+// MemorySink keeps the logs at or above MinLevel in memory. It is meant for tests.
+type MemorySink struct {
+	mu       sync.Mutex
+	MinLevel LogLevel
+	logs     []Log
+}
+
+// isLevelEnabled will check if a log level is at or above the minimum level.
+func isLevelEnabled(logLvl LogLevel, minLvl LogLevel) bool {
+	return logLvl >= minLvl
+}
+
+// formatLog will format the log with the formatter, defaulting to the text format.
+func formatLog(f Formatter, lg Log) string {
+	if f == nil {
+		return formatText(lg)
+	}
+	return f(lg)
+}
+
+// NewWriterSink will create a sink writing to w.
+func NewWriterSink(w io.Writer, minLvl LogLevel, f Formatter) *WriterSink {
+	return &WriterSink{
+		Writer:    w,
+		MinLevel:  minLvl,
+		Formatter: f,
+	}
+}
+
+// NewStdoutSink will create a sink writing to stdout.
+func NewStdoutSink(minLvl LogLevel, f Formatter) *WriterSink {
+	return NewWriterSink(os.Stdout, minLvl, f)
+}
+
+// NewStderrSink will create a sink writing to stderr.
+func NewStderrSink(minLvl LogLevel, f Formatter) *WriterSink {
+	return NewWriterSink(os.Stderr, minLvl, f)
+}
+
+// WriteLog will write the formatted log as a line to the writer.
+func (ws *WriterSink) WriteLog(lg Log) error {
+	if !isLevelEnabled(lg.LogLevel, ws.MinLevel) {
+		return nil
+	}
+	ws.mu.Lock()
+	defer ws.mu.Unlock()
+	_, err := io.WriteString(ws.Writer, formatLog(ws.Formatter, lg)+"\n")
+	return err
+}
+
+// NewFileSink will create a sink appending to the file at path. A maxBytes of 0 disables rotation.
+func NewFileSink(path string, maxBytes int64, maxBackups int, minLvl LogLevel, f Formatter) (*FileSink, error) {
+	fs := &FileSink{
+		Path:       path,
+		MaxBytes:   maxBytes,
+		MaxBackups: maxBackups,
+		MinLevel:   minLvl,
+		Formatter:  f,
+	}
+	if err := fs.open(); err != nil {
+		return nil, err
+	}
+	return fs, nil
+}
+
+// open will open the log file for appending.
+func (fs *FileSink) open() error {
+	file, err := os.OpenFile(fs.Path, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0644)
+	if err != nil {
+		return err
+	}
+	info, err := file.Stat()
+	if err != nil {
+		file.Close()
+		return err
+	}
+	fs.file = file
+	fs.size = info.Size()
+	return nil
+}
+
+// rotate will shift the rotated files by one, drop the oldest and start a new log file.
+func (fs *FileSink) rotate() error {
+	if err := fs.file.Close(); err != nil {
+		return err
+	}
+	if fs.MaxBackups > 0 {
+		os.Remove(fmt.Sprintf("%v.%d", fs.Path, fs.MaxBackups))
+		for i := fs.MaxBackups - 1; i > 0; i-- {
+			os.Rename(fmt.Sprintf("%v.%d", fs.Path, i), fmt.Sprintf("%v.%d", fs.Path, i+1))
+		}
+		if err := os.Rename(fs.Path, fs.Path+".1"); err != nil {
+			return err
+		}
+	} else if err := os.Remove(fs.Path); err != nil {
+		return err
+	}
+	return fs.open()
+}
+
+// WriteLog will append the formatted log as a line to the file, rotating it when full.
+func (fs *FileSink) WriteLog(lg Log) error {
+	if !isLevelEnabled(lg.LogLevel, fs.MinLevel) {
+		return nil
+	}
+	line := formatLog(fs.Formatter, lg) + "\n"
+	fs.mu.Lock()
+	defer fs.mu.Unlock()
+	if fs.file == nil {
+		return fmt.Errorf("file sink %v is closed", fs.Path)
+	}
+	if fs.MaxBytes > 0 && fs.size > 0 && fs.size+int64(len(line)) > fs.MaxBytes {
+		if err := fs.rotate(); err != nil {
+			return err
+		}
+	}
+	n, err := fs.file.WriteString(line)
+	fs.size += int64(n)
+	return err
+}
+
+// Close will close the log file.
+func (fs *FileSink) Close() error {
+	fs.mu.Lock()
+	defer fs.mu.Unlock()
+	if fs.file == nil {
+		return nil
+	}
+	err := fs.file.Close()
+	fs.file = nil
+	return err
+}
+
+// NewMemorySink will create an in-memory sink.
+func NewMemorySink(minLvl LogLevel) *MemorySink {
+	return &MemorySink{
+		MinLevel: minLvl,
+	}
+}
+
+// WriteLog will keep the log in memory.
+func (ms *MemorySink) WriteLog(lg Log) error {
+	if !isLevelEnabled(lg.LogLevel, ms.MinLevel) {
+		return nil
+	}
+	ms.mu.Lock()
+	defer ms.mu.Unlock()
+	ms.logs = append(ms.logs, lg)
+	return nil
+}
+
+// Logs will return a copy of the logs written to the sink in order.
+func (ms *MemorySink) Logs() []Log {
+	ms.mu.Lock()
+	defer ms.mu.Unlock()
+	return append([]Log{}, ms.logs...)
+}
+
+// Reset will remove all the logs written to the sink.
+func (ms *MemorySink) Reset() {
+	ms.mu.Lock()
+	defer ms.mu.Unlock()
+	ms.logs = nil
+}
+
+// writeLog will write the log to every sink of the logger. Without sinks the log is printed
+// to stdout in the format of the logger.
+func (lgr Logger) writeLog(lg Log) {
+	if len(lgr.Sinks) == 0 {
+		switch lgr.Format {
+		case JSON_FORMAT:
+			fmt.Println(formatJSON(lg))
+		case CONSOLE_FORMAT:
+			fmt.Println(ConsoleFormatter(lg))
+		default:
+			fmt.Println(formatText(lg))
+		}
+		return
+	}
+	for _, s := range lgr.Sinks {
+		if err := s.WriteLog(lg); err != nil {
+			fmt.Fprintln(os.Stderr, "logger: sink write failed.", err)
+		}
+	}
+}
@@ -0,0 +1,144 @@
+package logger
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+var writerSinkTests = []struct {
+	testName  string
+	minLevel  LogLevel
+	formatter Formatter
+	log       Log
+	wantLine  bool
+}{
+	{"text formatter", INFO, TextFormatter, Log{LogLevel: INFO, Text: "Test log"}, true},
+	{"default formatter", INFO, nil, Log{LogLevel: INFO, Text: "Test log"}, true},
+	{"json formatter", INFO, JSONFormatter, Log{LogLevel: INFO, Text: "Test log"}, true},
+	{"below minimum level", FATAL, TextFormatter, Log{LogLevel: INFO, Text: "Test log"}, false},
+}
+
+func TestWriterSink(t *testing.T) {
+	for _, tt := range writerSinkTests {
+		t.Run(tt.testName, func(t *testing.T) {
+			buf := &bytes.Buffer{}
+			sink := NewWriterSink(buf, tt.minLevel, tt.formatter)
+			if err := sink.WriteLog(tt.log); err != nil {
+				t.Fatalf("writer sink failed: %v", err)
+			}
+			if !tt.wantLine {
+				if buf.Len() != 0 {
+					t.Errorf("writer sink wrote log below minimum level: %v", buf.String())
+				}
+				return
+			}
+			want := formatLog(tt.formatter, tt.log) + "\n"
+			if buf.String() != want {
+				t.Errorf("writer sink got %q, want %q", buf.String(), want)
+			}
+		})
+	}
+}
+
+func TestFileSinkRotation(t *testing.T) {
+	dir, err := ioutil.TempDir("", "logger")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	path := filepath.Join(dir, "app.log")
+	lg := Log{LogLevel: INFO, Text: "Test log"}
+	lineLen := int64(len(formatText(lg)) + 1)
+	sink, err := NewFileSink(path, lineLen*2, 2, INFO, TextFormatter)
+	if err != nil {
+		t.Fatalf("file sink creation failed: %v", err)
+	}
+	for i := 0; i < 7; i++ {
+		if err := sink.WriteLog(lg); err != nil {
+			t.Fatalf("file sink write failed: %v", err)
+		}
+	}
+	if err := sink.Close(); err != nil {
+		t.Fatalf("file sink close failed: %v", err)
+	}
+
+	wantLines := map[string]int{path: 1, path + ".1": 2, path + ".2": 2}
+	for file, want := range wantLines {
+		content, err := ioutil.ReadFile(file)
+		if err != nil {
+			t.Fatalf("rotated file %v missing: %v", file, err)
+		}
+		if got := strings.Count(string(content), "\n"); got != want {
+			t.Errorf("file %v lines got %v, want %v", file, got, want)
+		}
+	}
+	if _, err := os.Stat(path + ".3"); !os.IsNotExist(err) {
+		t.Errorf("file sink kept more than MaxBackups rotated files")
+	}
+	if err := sink.WriteLog(lg); err == nil {
+		t.Errorf("closed file sink should fail to write")
+	}
+}
+
+func TestMemorySink(t *testing.T) {
+	sink := NewMemorySink(ERROR)
+	sink.WriteLog(Log{LogLevel: INFO, Text: "Info log"})
+	sink.WriteLog(Log{LogLevel: ERROR, Text: "Error log"})
+	sink.WriteLog(Log{LogLevel: FATAL, Text: "Fatal log"})
+
+	logs := sink.Logs()
+	if len(logs) != 2 || logs[0].Text != "Error log" || logs[1].Text != "Fatal log" {
+		t.Errorf("memory sink logs got %v", logs)
+	}
+	sink.Reset()
+	if len(sink.Logs()) != 0 {
+		t.Errorf("memory sink not reset")
+	}
+}
+
+type failingSink struct{}
+
+func (failingSink) WriteLog(lg Log) error {
+	return errors.New("sink unavailable")
+}
+
+func TestLoggerMultipleSinks(t *testing.T) {
+	memory := NewMemorySink(INFO)
+	buf := &bytes.Buffer{}
+	lgr := NewLogger()
+	lgr.Sinks = []Sink{memory, failingSink{}, NewWriterSink(buf, INFO, JSONFormatter)}
+	lgr.LogTxt(INFO, "First log")
+	lgr.LogTxt(ERROR, "Second log")
+
+	stdout := captureStdout(lgr.DisplayLogsBackward)
+	if stdout != "" {
+		t.Errorf("logger with sinks printed to stdout: %v", stdout)
+	}
+	logs := memory.Logs()
+	if len(logs) != 2 || logs[0].Text != "First log" || logs[1].Text != "Second log" {
+		t.Errorf("memory sink logs got %v", logs)
+	}
+	lines := strings.Split(strings.TrimSpace(buf.String()), "\n")
+	if len(lines) != 2 {
+		t.Fatalf("writer sink lines got %v, want 2", len(lines))
+	}
+	for _, line := range lines {
+		if !json.Valid([]byte(line)) {
+			t.Errorf("writer sink line is not json: %v", line)
+		}
+	}
+
+	derived := lgr.Derive()
+	derived.LogTxt(INFO, "Derived log")
+	derived.DisplayLogsForward()
+	if len(memory.Logs()) != 3 {
+		t.Errorf("derived logger does not share the sinks")
+	}
+}
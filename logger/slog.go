@@ -0,0 +1,174 @@
+//go:build go1.21
+// +build go1.21
+
+package logger
+
+import (
+	"context"
+	"log/slog"
+	"runtime"
+	"time"
+)
+
+// slog levels of the TRACE and FATAL log levels that have no slog.Level constant
+const (
+	SLOG_LEVEL_TRACE = slog.Level(-8)
+	SLOG_LEVEL_FATAL = slog.Level(12)
+)
+
+// SlogHandler is a log/slog Handler recording the slog records as logs of a Logger, so libraries logging
+// through slog get the levels, ids, redaction and format of the Logger. Records logged with a context
+// carrying a logger, e.g. slog.InfoContext(ctx, ..) in a service function, are recorded by the logger of
+// the context instead. The attributes are recorded as the log data with the groups as nested maps.
+type SlogHandler struct {
+	lgr    Logger
+	data   map[string]interface{}
+	groups []string
+}
+
+// NewSlogHandler will create a slog handler recording to lgr.
+func NewSlogHandler(lgr Logger) *SlogHandler {
+	return &SlogHandler{
+		lgr: lgr,
+	}
+}
+
+// fromSlogLevel will map a slog level to the log level it is at or above.
+func fromSlogLevel(lvl slog.Level) LogLevel {
+	switch {
+	case lvl < slog.LevelDebug:
+		return TRACE
+	case lvl < slog.LevelInfo:
+		return DEBUG
+	case lvl < slog.LevelWarn:
+		return INFO
+	case lvl < slog.LevelError:
+		return WARN
+	case lvl < SLOG_LEVEL_FATAL:
+		return ERROR
+	default:
+		return FATAL
+	}
+}
+
+// logger will return the logger of ctx, or the logger of the handler without one.
+func (sh *SlogHandler) logger(ctx context.Context) Logger {
+	if lgr, ok := loggerFromContext(ctx); ok {
+		return lgr
+	}
+	return sh.lgr
+}
+
+// Enabled will check if the records of the slog level are recorded by the logger.
+func (sh *SlogHandler) Enabled(ctx context.Context, lvl slog.Level) bool {
+	return sh.logger(ctx).Enabled(fromSlogLevel(lvl))
+}
+
+// Handle will record the slog record as a log of the logger.
+func (sh *SlogHandler) Handle(ctx context.Context, r slog.Record) error {
+	lgr := sh.logger(ctx)
+	data := copySlogData(sh.data)
+	r.Attrs(func(a slog.Attr) bool {
+		data = addSlogAttr(data, sh.groups, a)
+		return true
+	})
+	function, file, line := "slog", "", 0
+	if r.PC != 0 {
+		frame, _ := runtime.CallersFrames([]uintptr{r.PC}).Next()
+		function, file, line = frame.Function, frame.File, frame.Line
+	}
+	lg := lgr.newLogAt(function, file, line, fromSlogLevel(r.Level), r.Message, data)
+	if !r.Time.IsZero() {
+		lg.Time = r.Time
+		lg.TimeStamp = r.Time.Format(time.RFC850)
+	}
+	lgr.record(lg)
+	return nil
+}
+
+// WithAttrs will create a handler adding the attributes to every record in the current group.
+func (sh *SlogHandler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	if len(attrs) == 0 {
+		return sh
+	}
+	derived := *sh
+	derived.data = copySlogData(sh.data)
+	for _, a := range attrs {
+		derived.data = addSlogAttr(derived.data, sh.groups, a)
+	}
+	return &derived
+}
+
+// WithGroup will create a handler nesting the attributes added next under the group.
+func (sh *SlogHandler) WithGroup(name string) slog.Handler {
+	if name == "" {
+		return sh
+	}
+	derived := *sh
+	derived.groups = append(append([]string{}, sh.groups...), name)
+	return &derived
+}
+
+// copySlogData will deep copy the nested maps of the log data.
+func copySlogData(data map[string]interface{}) map[string]interface{} {
+	if data == nil {
+		return nil
+	}
+	copied := make(map[string]interface{}, len(data))
+	for k, v := range data {
+		if group, ok := v.(map[string]interface{}); ok {
+			v = copySlogData(group)
+		}
+		copied[k] = v
+	}
+	return copied
+}
+
+// addSlogAttr will add the attribute to the log data nested under the groups. Empty attributes are
+// ignored and group attributes without a key are inlined, like the slog built-in handlers do.
+func addSlogAttr(data map[string]interface{}, groups []string, a slog.Attr) map[string]interface{} {
+	a.Value = a.Value.Resolve()
+	if a.Equal(slog.Attr{}) {
+		return data
+	}
+	if a.Value.Kind() == slog.KindGroup {
+		groupAttrs := a.Value.Group()
+		if len(groupAttrs) == 0 {
+			return data
+		}
+		if a.Key != "" {
+			groups = append(append([]string{}, groups...), a.Key)
+		}
+		for _, ga := range groupAttrs {
+			data = addSlogAttr(data, groups, ga)
+		}
+		return data
+	}
+
+	if data == nil {
+		data = map[string]interface{}{}
+	}
+	target := data
+	for _, g := range groups {
+		group, ok := target[g].(map[string]interface{})
+		if !ok {
+			group = map[string]interface{}{}
+			target[g] = group
+		}
+		target = group
+	}
+	target[a.Key] = slogValue(a.Value)
+	return data
+}
+
+// slogValue will convert a resolved slog value to a log data value. Any values are flattened like
+// logged objects, so the redactor sees the fields of structs and maps.
+func slogValue(v slog.Value) interface{} {
+	switch v.Kind() {
+	case slog.KindDuration:
+		return v.Duration().String()
+	case slog.KindAny:
+		return toLogValue(v.Any())
+	}
+	return v.Any()
+}
@@ -0,0 +1,134 @@
+//go:build go1.21
+// +build go1.21
+
+package logger
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"reflect"
+	"testing"
+	"time"
+)
+
+var fromSlogLevelTests = []struct {
+	testName string
+	level    slog.Level
+	want     LogLevel
+}{
+	{"trace", SLOG_LEVEL_TRACE, TRACE},
+	{"debug", slog.LevelDebug, DEBUG},
+	{"info", slog.LevelInfo, INFO},
+	{"between info and warn", slog.LevelInfo + 2, INFO},
+	{"warn", slog.LevelWarn, WARN},
+	{"error", slog.LevelError, ERROR},
+	{"fatal", SLOG_LEVEL_FATAL, FATAL},
+}
+
+func TestFromSlogLevel(t *testing.T) {
+	for _, tt := range fromSlogLevelTests {
+		t.Run(tt.testName, func(t *testing.T) {
+			if got := fromSlogLevel(tt.level); got != tt.want {
+				t.Errorf("log level got %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+// newSlogTestLogger will create a streaming logger writing to a memory sink
+func newSlogTestLogger(minLvl LogLevel) (Logger, *MemorySink) {
+	sink := NewMemorySink(TRACE)
+	lgr := NewLogger()
+	lgr.Mode = STREAM_MODE
+	lgr.Sinks = []Sink{sink}
+	lgr.MinLevel = minLvl
+	return lgr, sink
+}
+
+func TestSlogHandler(t *testing.T) {
+	lgr, sink := newSlogTestLogger(INFO)
+	lgr.CorrelationID = "correlation-1"
+	slgr := slog.New(NewSlogHandler(lgr)).With("service", "users").WithGroup("request")
+
+	slgr.Debug("Discarded below the minimum level")
+	slgr.Info("Creating user",
+		"userId", "1234",
+		"password", "secret",
+		slog.Group("timing", "elapsed", 1500*time.Millisecond),
+		slog.Group("", "inlined", true),
+		slog.Attr{},
+	)
+	slgr.Error("Creating user failed", "err", errors.New("conflict"))
+
+	logs := sink.Logs()
+	if len(logs) != 2 {
+		t.Fatalf("slog handler logs got %v, want 2", len(logs))
+	}
+	wantData := map[string]interface{}{
+		"service": "users",
+		"request": map[string]interface{}{
+			"userId":   "1234",
+			"password": REDACTED,
+			"timing":   map[string]interface{}{"elapsed": "1.5s"},
+			"inlined":  true,
+		},
+	}
+	info := logs[0]
+	if info.Text != "Creating user" || info.LogLevel != INFO || !reflect.DeepEqual(info.Data, wantData) {
+		t.Errorf("slog info log got %v %v %v, want data %v", info.LogLevel, info.Text, info.Data, wantData)
+	}
+	if info.CorrelationID != "correlation-1" || info.ModuleName != "logger.TestSlogHandler" {
+		t.Errorf("slog log correlation id %v module %v", info.CorrelationID, info.ModuleName)
+	}
+	failure := logs[1]
+	if failure.LogLevel != ERROR || failure.Data["request"].(map[string]interface{})["err"] != "conflict" ||
+		failure.Caller == "" {
+		t.Errorf("slog error log got %v %v caller %v", failure.LogLevel, failure.Data, failure.Caller)
+	}
+}
+
+// slogCredentials is a struct with a sensitive field logged as a slog any value
+type slogCredentials struct {
+	User     string `json:"user"`
+	Password string `json:"password"`
+}
+
+func TestSlogHandlerAnyValues(t *testing.T) {
+	lgr, sink := newSlogTestLogger(INFO)
+	slog.New(NewSlogHandler(lgr)).Info("login",
+		"req", slogCredentials{User: "bob", Password: "hunter2"},
+		"headers", map[string]string{"Authorization": "Bearer xyz"},
+		"err", errors.New("invalid credentials"),
+	)
+
+	want := map[string]interface{}{
+		"req":     map[string]interface{}{"user": "bob", "password": REDACTED},
+		"headers": map[string]interface{}{"Authorization": REDACTED},
+		"err":     "invalid credentials",
+	}
+	if logs := sink.Logs(); len(logs) != 1 || !reflect.DeepEqual(logs[0].Data, want) {
+		t.Errorf("slog any values got %v, want %v", sink.Logs(), want)
+	}
+}
+
+func TestSlogHandlerContextLogger(t *testing.T) {
+	baseLgr, baseSink := newSlogTestLogger(INFO)
+	reqLgr, reqSink := newSlogTestLogger(DEBUG)
+	reqLgr.RequestID = "request-1"
+	slgr := slog.New(NewSlogHandler(baseLgr))
+
+	ctx := IntoContext(context.Background(), reqLgr)
+	slgr.DebugContext(ctx, "Fetching user")
+	slgr.InfoContext(context.Background(), "Process started")
+
+	if got := sinkTexts(reqSink); !reflect.DeepEqual(got, []string{"Fetching user"}) {
+		t.Errorf("request logger logs got %v", got)
+	}
+	if reqSink.Logs()[0].RequestID != "request-1" {
+		t.Errorf("slog record not recorded with the request id")
+	}
+	if got := sinkTexts(baseSink); !reflect.DeepEqual(got, []string{"Process started"}) {
+		t.Errorf("base logger logs got %v", got)
+	}
+}
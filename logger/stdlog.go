@@ -0,0 +1,58 @@
+package logger
+
+import (
+	"log"
+	"runtime"
+	"strings"
+)
+
+// stdLogWriter writes the output of a standard library logger as logs of a Logger at a log level.
+type stdLogWriter struct {
+	lgr    Logger
+	logLvl LogLevel
+}
+
+// stdLogCaller will return the function, file and line of the first caller outside of the log package.
+func stdLogCaller() (string, string, int) {
+	pcs := make([]uintptr, 16)
+	n := runtime.Callers(3, pcs)
+	frames := runtime.CallersFrames(pcs[:n])
+	frame, more := frames.Next()
+	for more && strings.HasPrefix(frame.Function, "log.") {
+		frame, more = frames.Next()
+	}
+	return frame.Function, frame.File, frame.Line
+}
+
+// Write will log each line written by the standard library logger.
+func (sw stdLogWriter) Write(p []byte) (int, error) {
+	if !sw.lgr.Enabled(sw.logLvl) {
+		return len(p), nil
+	}
+	function, file, line := stdLogCaller()
+	for _, txt := range strings.Split(strings.TrimRight(string(p), "\n"), "\n") {
+		sw.lgr.record(sw.lgr.newLogAt(function, file, line, sw.logLvl, txt, nil))
+	}
+	return len(p), nil
+}
+
+// NewStdLogger will create a standard library logger writing to lgr at the log level, e.g. for the
+// ErrorLog of an http.Server.
+func NewStdLogger(lgr Logger, logLvl LogLevel) *log.Logger {
+	return log.New(stdLogWriter{lgr: lgr, logLvl: logLvl}, "", 0)
+}
+
+// RedirectStdLog will redirect the output of the standard log package to lgr at the log level, so the
+// logs of libraries using it get the level, ids and format of lgr. The timestamp and prefix flags of the
+// standard logger are cleared as lgr adds its own. It returns the function restoring the standard logger.
+func RedirectStdLog(lgr Logger, logLvl LogLevel) func() {
+	output, flags, prefix := log.Writer(), log.Flags(), log.Prefix()
+	log.SetOutput(stdLogWriter{lgr: lgr, logLvl: logLvl})
+	log.SetFlags(0)
+	log.SetPrefix("")
+	return func() {
+		log.SetOutput(output)
+		log.SetFlags(flags)
+		log.SetPrefix(prefix)
+	}
+}
@@ -0,0 +1,53 @@
+package logger
+
+import (
+	"log"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestRedirectStdLog(t *testing.T) {
+	sink := NewMemorySink(TRACE)
+	lgr := NewLogger()
+	lgr.Mode = STREAM_MODE
+	lgr.Sinks = []Sink{sink}
+	lgr.CorrelationID = "correlation-1"
+
+	restore := RedirectStdLog(lgr, WARN)
+	log.Println("Library warning")
+	log.Printf("Retrying %v\nfor user %v", 2, "juan@email.com")
+	restore()
+	log.SetOutput(&strings.Builder{})
+	log.Println("After restore")
+	restore()
+
+	want := []string{"Library warning", "Retrying 2", "for user [REDACTED]"}
+	if got := sinkTexts(sink); !reflect.DeepEqual(got, want) {
+		t.Fatalf("redirected logs got %v, want %v", got, want)
+	}
+	for _, lg := range sink.Logs() {
+		if lg.LogLevel != WARN || lg.CorrelationID != "correlation-1" || lg.ModuleName != "logger.TestRedirectStdLog" {
+			t.Errorf("redirected log got level %v correlation id %v module %v", lg.LogLevel, lg.CorrelationID, lg.ModuleName)
+		}
+	}
+}
+
+func TestNewStdLogger(t *testing.T) {
+	sink := NewMemorySink(TRACE)
+	lgr := NewLogger()
+	lgr.Mode = STREAM_MODE
+	lgr.Sinks = []Sink{sink}
+	lgr.MinLevel = ERROR
+
+	NewStdLogger(lgr, ERROR).Print("http: TLS handshake error")
+	NewStdLogger(lgr, INFO).Print("discarded below the minimum level")
+
+	logs := sink.Logs()
+	if len(logs) != 1 || logs[0].Text != "http: TLS handshake error" || logs[0].LogLevel != ERROR {
+		t.Fatalf("std logger logs got %v", logs)
+	}
+	if !strings.HasPrefix(logs[0].Caller, "logger/stdlog_test.go:") {
+		t.Errorf("std logger error log caller got %v", logs[0].Caller)
+	}
+}
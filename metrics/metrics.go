@@ -0,0 +1,285 @@
+package metrics
+
+import (
+	"context"
+	"encoding/json"
+	"go-micro/logger"
+	"math"
+	"os"
+	"sort"
+	"sync"
+	"time"
+)
+
+// Unit is the CloudWatch unit of a metric.
+type Unit string
+
+const (
+	NONE             Unit = "None"
+	COUNT            Unit = "Count"
+	PERCENT          Unit = "Percent"
+	SECONDS          Unit = "Seconds"
+	MILLISECONDS     Unit = "Milliseconds"
+	MICROSECONDS     Unit = "Microseconds"
+	BYTES            Unit = "Bytes"
+	KILOBYTES        Unit = "Kilobytes"
+	MEGABYTES        Unit = "Megabytes"
+	GIGABYTES        Unit = "Gigabytes"
+	BYTES_PER_SECOND Unit = "Bytes/Second"
+	COUNT_PER_SECOND Unit = "Count/Second"
+)
+
+// Embedded Metric Format limits of a single document
+const (
+	MAX_METRICS_PER_DOCUMENT = 100
+	MAX_VALUES_PER_METRIC    = 100
+	MAX_DIMENSIONS_PER_SET   = 30
+)
+
+// METRICS_NAMESPACE_ENV is the environment variable of the DefaultNamespace
+const METRICS_NAMESPACE_ENV = "METRICS_NAMESPACE"
+
+// DefaultNamespace and DefaultSink are the namespace and sink of the metrics created by NewInvocationMetrics.
+var (
+	DefaultNamespace      = defaultNamespace()
+	DefaultSink      Sink = NewWriterSink(os.Stdout)
+)
+
+// now is the clock of the metric timestamps that can be mocked in testing
+var now = time.Now
+
+// defaultNamespace will return the namespace of the METRICS_NAMESPACE environment variable, or the
+// name of the lambda function.
+func defaultNamespace() string {
+	if namespace := os.Getenv(METRICS_NAMESPACE_ENV); namespace != "" {
+		return namespace
+	}
+	if logger.FunctionName != "" {
+		return logger.FunctionName
+	}
+	return "go-micro"
+}
+
+// metric is a metric with the values recorded since the last flush.
+type metric struct {
+	name   string
+	unit   Unit
+	values []float64
+}
+
+// MetricsLogger records metrics and emits them as CloudWatch Embedded Metric Format JSON documents
+// on Flush. Every dimension set aggregates all the metrics of the logger. It is safe for concurrent use.
+type MetricsLogger struct {
+	mu         sync.Mutex
+	Namespace  string
+	Sink       Sink
+	dimensions []map[string]string
+	properties map[string]interface{}
+	metrics    map[string]*metric
+	order      []string
+}
+
+// NewMetricsLogger will create a metrics logger emitting to the sink under the namespace.
+func NewMetricsLogger(namespace string, sink Sink) *MetricsLogger {
+	return &MetricsLogger{
+		Namespace:  namespace,
+		Sink:       sink,
+		properties: map[string]interface{}{},
+		metrics:    map[string]*metric{},
+	}
+}
+
+// NewInvocationMetrics will create the metrics logger of an invocation with the DefaultNamespace and
+// DefaultSink. The request and correlation ids of the request logger are set as properties.
+func NewInvocationMetrics(lgr logger.Logger) *MetricsLogger {
+	m := NewMetricsLogger(DefaultNamespace, DefaultSink)
+	if logger.FunctionName != "" {
+		m.PutDimensions(map[string]string{"FunctionName": logger.FunctionName})
+	}
+	if lgr.RequestID != "" {
+		m.SetProperty("requestId", lgr.RequestID)
+	}
+	if lgr.CorrelationID != "" {
+		m.SetProperty("correlationId", lgr.CorrelationID)
+	}
+	return m
+}
+
+// PutMetric will record a value of the metric. The unit of the first value of a metric is kept.
+// NaN and infinite values are ignored as CloudWatch rejects them.
+func (m *MetricsLogger) PutMetric(name string, value float64, unit Unit) {
+	if math.IsNaN(value) || math.IsInf(value, 0) {
+		return
+	}
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	mt, ok := m.metrics[name]
+	if !ok {
+		mt = &metric{name: name, unit: unit}
+		m.metrics[name] = mt
+		m.order = append(m.order, name)
+	}
+	mt.values = append(mt.values, value)
+}
+
+// PutDimensions will add a dimension set to aggregate the metrics by. Dimension sets larger than
+// MAX_DIMENSIONS_PER_SET keep their first dimensions in key order.
+func (m *MetricsLogger) PutDimensions(dims map[string]string) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.dimensions = append(m.dimensions, limitDimensions(dims))
+}
+
+// SetDimensions will replace the dimension sets to aggregate the metrics by.
+func (m *MetricsLogger) SetDimensions(dimSets ...map[string]string) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.dimensions = nil
+	for _, dims := range dimSets {
+		m.dimensions = append(m.dimensions, limitDimensions(dims))
+	}
+}
+
+// SetProperty will set a property that is emitted with the metrics, e.g. to search the documents in logs.
+func (m *MetricsLogger) SetProperty(key string, value interface{}) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.properties[key] = value
+}
+
+// limitDimensions will copy the dimension set, keeping MAX_DIMENSIONS_PER_SET dimensions.
+func limitDimensions(dims map[string]string) map[string]string {
+	keys := sortedKeys(dims)
+	if len(keys) > MAX_DIMENSIONS_PER_SET {
+		keys = keys[:MAX_DIMENSIONS_PER_SET]
+	}
+	limited := make(map[string]string, len(keys))
+	for _, k := range keys {
+		limited[k] = dims[k]
+	}
+	return limited
+}
+
+// sortedKeys will return the keys of the dimension set in order.
+func sortedKeys(dims map[string]string) []string {
+	keys := make([]string, 0, len(dims))
+	for k := range dims {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// documents will build the EMF documents of the recorded metrics, splitting them over several documents
+// to stay within MAX_METRICS_PER_DOCUMENT and MAX_VALUES_PER_METRIC.
+func (m *MetricsLogger) documents() []map[string]interface{} {
+	// Metrics without dimensions are emitted with a single empty dimension set
+	dimensionSets := [][]string{}
+	if len(m.dimensions) == 0 {
+		dimensionSets = append(dimensionSets, []string{})
+	}
+	dimensionValues := map[string]string{}
+	for _, dims := range m.dimensions {
+		dimensionSets = append(dimensionSets, sortedKeys(dims))
+		for k, v := range dims {
+			dimensionValues[k] = v
+		}
+	}
+
+	remaining := make([]*metric, 0, len(m.order))
+	for _, name := range m.order {
+		remaining = append(remaining, m.metrics[name])
+	}
+	offsets := map[string]int{}
+	timestamp := now().UnixNano() / int64(time.Millisecond)
+
+	docs := []map[string]interface{}{}
+	for len(remaining) > 0 {
+		doc := map[string]interface{}{}
+		for k, v := range m.properties {
+			doc[k] = v
+		}
+		for k, v := range dimensionValues {
+			doc[k] = v
+		}
+		// Metrics left out of the document come first in the next one, before the remaining values
+		// of the metrics in the document
+		definitions := []map[string]interface{}{}
+		next, partial := []*metric{}, []*metric{}
+		for i, mt := range remaining {
+			if i >= MAX_METRICS_PER_DOCUMENT {
+				next = append(next, mt)
+				continue
+			}
+			start := offsets[mt.name]
+			end := start + MAX_VALUES_PER_METRIC
+			if end > len(mt.values) {
+				end = len(mt.values)
+			}
+			if end-start == 1 {
+				doc[mt.name] = mt.values[start]
+			} else {
+				doc[mt.name] = mt.values[start:end]
+			}
+			definitions = append(definitions, map[string]interface{}{"Name": mt.name, "Unit": mt.unit})
+			offsets[mt.name] = end
+			if end < len(mt.values) {
+				partial = append(partial, mt)
+			}
+		}
+		doc["_aws"] = map[string]interface{}{
+			"Timestamp": timestamp,
+			"CloudWatchMetrics": []map[string]interface{}{{
+				"Namespace":  m.Namespace,
+				"Dimensions": dimensionSets,
+				"Metrics":    definitions,
+			}},
+		}
+		docs = append(docs, doc)
+		remaining = append(next, partial...)
+	}
+	return docs
+}
+
+// Flush will emit the recorded metrics as EMF documents to the sink and reset them. The namespace,
+// dimensions and properties are kept for the next flush. Nothing is emitted without metrics.
+func (m *MetricsLogger) Flush() error {
+	m.mu.Lock()
+	docs := m.documents()
+	m.metrics = map[string]*metric{}
+	m.order = nil
+	m.mu.Unlock()
+
+	for _, doc := range docs {
+		line, err := json.Marshal(doc)
+		if err != nil {
+			return err
+		}
+		if err := m.Sink.WriteMetrics(line); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// contextKey is the key of the metrics logger in a context.Context
+type contextKey struct{}
+
+// IntoContext will return a copy of ctx carrying the metrics logger.
+func IntoContext(ctx context.Context, m *MetricsLogger) context.Context {
+	if ctx == nil {
+		ctx = context.Background()
+	}
+	return context.WithValue(ctx, contextKey{}, m)
+}
+
+// FromContext will return the metrics logger carried by ctx. Without one, a new metrics logger with the
+// DefaultNamespace and DefaultSink is returned that the caller has to flush.
+func FromContext(ctx context.Context) *MetricsLogger {
+	if ctx != nil {
+		if m, ok := ctx.Value(contextKey{}).(*MetricsLogger); ok {
+			return m
+		}
+	}
+	return NewMetricsLogger(DefaultNamespace, DefaultSink)
+}
@@ -0,0 +1,175 @@
+package metrics
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"go-micro/logger"
+	"math"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+// mockNow will fix the clock of the metric timestamps and return the function restoring it
+func mockNow() func() {
+	now = func() time.Time { return time.Date(2021, 5, 1, 10, 30, 0, 0, time.UTC) }
+	return func() { now = time.Now }
+}
+
+func TestMetricsLoggerFlush(t *testing.T) {
+	defer mockNow()()
+	sink := NewMemorySink()
+	m := NewMetricsLogger("go-micro/users", sink)
+	m.PutDimensions(map[string]string{"Service": "users", "Stage": "dev"})
+	m.PutDimensions(map[string]string{"Service": "users"})
+	m.SetProperty("requestId", "request-1")
+	m.PutMetric("UsersCreated", 1, COUNT)
+	m.PutMetric("DownstreamLatency", 12.5, MILLISECONDS)
+	m.PutMetric("DownstreamLatency", 20, SECONDS)
+	m.PutMetric("Invalid", math.NaN(), COUNT)
+	if err := m.Flush(); err != nil {
+		t.Fatalf("metrics flush failed: %v", err)
+	}
+
+	docs := sink.Documents()
+	if len(docs) != 1 {
+		t.Fatalf("documents got %v, want 1", len(docs))
+	}
+	want := map[string]interface{}{
+		"_aws": map[string]interface{}{
+			"Timestamp": float64(1619865000000),
+			"CloudWatchMetrics": []interface{}{map[string]interface{}{
+				"Namespace":  "go-micro/users",
+				"Dimensions": []interface{}{[]interface{}{"Service", "Stage"}, []interface{}{"Service"}},
+				"Metrics": []interface{}{
+					map[string]interface{}{"Name": "UsersCreated", "Unit": "Count"},
+					map[string]interface{}{"Name": "DownstreamLatency", "Unit": "Milliseconds"},
+				},
+			}},
+		},
+		"Service":           "users",
+		"Stage":             "dev",
+		"requestId":         "request-1",
+		"UsersCreated":      float64(1),
+		"DownstreamLatency": []interface{}{12.5, float64(20)},
+	}
+	if !reflect.DeepEqual(docs[0], want) {
+		t.Errorf("emf document got %v, want %v", docs[0], want)
+	}
+
+	// Metrics are reset while the dimensions and properties are kept
+	sink.Reset()
+	m.Flush()
+	if len(sink.Documents()) != 0 {
+		t.Errorf("flush without metrics emitted documents")
+	}
+	m.PutMetric("UsersCreated", 2, COUNT)
+	m.Flush()
+	if docs = sink.Documents(); len(docs) != 1 || docs[0]["requestId"] != "request-1" || docs[0]["Stage"] != "dev" {
+		t.Errorf("second flush got %v", docs)
+	}
+}
+
+func TestMetricsLoggerNoDimensions(t *testing.T) {
+	sink := NewMemorySink()
+	m := NewMetricsLogger("go-micro", sink)
+	m.PutMetric("UsersCreated", 1, COUNT)
+	m.Flush()
+	directive := sink.Documents()[0]["_aws"].(map[string]interface{})["CloudWatchMetrics"].([]interface{})[0]
+	if dims := directive.(map[string]interface{})["Dimensions"]; !reflect.DeepEqual(dims, []interface{}{[]interface{}{}}) {
+		t.Errorf("dimensions without dimension sets got %v", dims)
+	}
+}
+
+var metricsLimitTests = []struct {
+	testName       string
+	metricCount    int
+	valuesPerMetic int
+	wantDocuments  int
+}{
+	{"within limits", 10, 10, 1},
+	{"too many metrics", MAX_METRICS_PER_DOCUMENT + 1, 1, 2},
+	{"too many values", 1, MAX_VALUES_PER_METRIC*2 + 1, 3},
+	{"too many metrics and values", MAX_METRICS_PER_DOCUMENT + 50, MAX_VALUES_PER_METRIC + 1, 3},
+}
+
+func TestMetricsLoggerLimits(t *testing.T) {
+	for _, tt := range metricsLimitTests {
+		t.Run(tt.testName, func(t *testing.T) {
+			sink := NewMemorySink()
+			m := NewMetricsLogger("go-micro", sink)
+			for i := 0; i < tt.metricCount; i++ {
+				for j := 0; j < tt.valuesPerMetic; j++ {
+					m.PutMetric(fmt.Sprintf("Metric%d", i), float64(j), COUNT)
+				}
+			}
+			m.Flush()
+			docs := sink.Documents()
+			if len(docs) != tt.wantDocuments {
+				t.Errorf("documents got %v, want %v", len(docs), tt.wantDocuments)
+			}
+			for _, doc := range docs {
+				directive := doc["_aws"].(map[string]interface{})["CloudWatchMetrics"].([]interface{})[0]
+				if len(directive.(map[string]interface{})["Metrics"].([]interface{})) > MAX_METRICS_PER_DOCUMENT {
+					t.Errorf("document exceeds the metrics limit")
+				}
+			}
+			for i := 0; i < tt.metricCount; i++ {
+				if values := sink.Values(fmt.Sprintf("Metric%d", i)); len(values) != tt.valuesPerMetic {
+					t.Errorf("metric values got %v, want %v", len(values), tt.valuesPerMetic)
+				}
+			}
+		})
+	}
+}
+
+func TestMetricsLoggerDimensionLimit(t *testing.T) {
+	dims := map[string]string{}
+	for i := 0; i < MAX_DIMENSIONS_PER_SET+5; i++ {
+		dims[fmt.Sprintf("Dim%02d", i)] = "value"
+	}
+	m := NewMetricsLogger("go-micro", NewMemorySink())
+	m.PutDimensions(dims)
+	if len(m.dimensions[0]) != MAX_DIMENSIONS_PER_SET {
+		t.Errorf("dimension set got %v dimensions, want %v", len(m.dimensions[0]), MAX_DIMENSIONS_PER_SET)
+	}
+	m.SetDimensions(map[string]string{"Service": "users"})
+	if len(m.dimensions) != 1 || m.dimensions[0]["Service"] != "users" {
+		t.Errorf("dimension sets not replaced, got %v", m.dimensions)
+	}
+}
+
+func TestWriterSink(t *testing.T) {
+	buf := &bytes.Buffer{}
+	m := NewMetricsLogger("go-micro", NewWriterSink(buf))
+	m.PutMetric("UsersCreated", 1, COUNT)
+	m.Flush()
+	line := strings.TrimSuffix(buf.String(), "\n")
+	if strings.Contains(line, "\n") || !json.Valid([]byte(line)) {
+		t.Errorf("writer sink line is not a single json line: %v", buf.String())
+	}
+}
+
+func TestInvocationMetricsContext(t *testing.T) {
+	defaultSink := DefaultSink
+	defer func() { DefaultSink = defaultSink }()
+	sink := NewMemorySink()
+	DefaultSink = sink
+
+	lgr := logger.NewLogger()
+	lgr.RequestID, lgr.CorrelationID = "request-1", "correlation-1"
+	m := NewInvocationMetrics(lgr)
+	ctx := IntoContext(context.Background(), m)
+	FromContext(ctx).PutMetric("UsersCreated", 1, COUNT)
+	m.Flush()
+	docs := sink.Documents()
+	if len(docs) != 1 || docs[0]["requestId"] != "request-1" || docs[0]["correlationId"] != "correlation-1" {
+		t.Errorf("invocation metrics documents got %v", docs)
+	}
+	if FromContext(context.Background()) == m {
+		t.Errorf("context without metrics logger should return a new metrics logger")
+	}
+}
@@ -0,0 +1,174 @@
+package metrics
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+// PROMETHEUS_CONTENT_TYPE is the content type of the Prometheus text exposition format
+const PROMETHEUS_CONTENT_TYPE = "text/plain; version=0.0.4; charset=utf-8"
+
+// DefaultBuckets are the upper bounds in seconds of the phase duration histogram buckets.
+var DefaultBuckets = []float64{0.001, 0.005, 0.01, 0.025, 0.05, 0.1, 0.25, 0.5, 1, 2.5, 5, 10}
+
+// Names and help texts of the Prometheus metrics
+const (
+	promInvocations        = "gomicro_invocations_total"
+	promColdStarts         = "gomicro_cold_starts_total"
+	promResponses          = "gomicro_responses_total"
+	promValidationFailures = "gomicro_validation_failures_total"
+	promPhaseDuration      = "gomicro_phase_duration_seconds"
+)
+
+var promHelp = map[string]string{
+	promInvocations:        "Number of endpoint invocations.",
+	promColdStarts:         "Number of endpoint invocations on a cold start.",
+	promResponses:          "Number of endpoint responses by status class.",
+	promValidationFailures: "Number of request validation failures by parameter location.",
+	promPhaseDuration:      "Duration of the endpoint invocation phases.",
+}
+
+// histogram is a cumulative histogram of the durations of a phase
+type histogram struct {
+	counts []uint64
+	sum    float64
+	count  uint64
+}
+
+// PrometheusRecorder aggregates the request metrics in memory and serves them in the Prometheus text
+// exposition format, e.g. on /metrics of the local server. It implements http.Handler. Buckets must not
+// change once requests are recorded.
+type PrometheusRecorder struct {
+	mu         sync.Mutex
+	Buckets    []float64
+	counters   map[string]map[string]float64
+	histograms map[string]*histogram
+}
+
+// NewPrometheusRecorder will create a recorder with the DefaultBuckets.
+func NewPrometheusRecorder() *PrometheusRecorder {
+	return &PrometheusRecorder{
+		Buckets:    DefaultBuckets,
+		counters:   map[string]map[string]float64{},
+		histograms: map[string]*histogram{},
+	}
+}
+
+// promLabels will format the label pairs of a sample, escaping the label values.
+func promLabels(pairs ...string) string {
+	labels := make([]string, 0, len(pairs)/2)
+	for i := 0; i+1 < len(pairs); i += 2 {
+		value := strings.NewReplacer(`\`, `\\`, `"`, `\"`, "\n", `\n`).Replace(pairs[i+1])
+		labels = append(labels, pairs[i]+`="`+value+`"`)
+	}
+	return strings.Join(labels, ",")
+}
+
+// add will increase the counter of the labels.
+func (pr *PrometheusRecorder) add(name string, labels string, value float64) {
+	if pr.counters[name] == nil {
+		pr.counters[name] = map[string]float64{}
+	}
+	pr.counters[name][labels] += value
+}
+
+// observe will add the duration in seconds to the phase histogram of the labels.
+func (pr *PrometheusRecorder) observe(labels string, seconds float64) {
+	h, ok := pr.histograms[labels]
+	if !ok {
+		h = &histogram{counts: make([]uint64, len(pr.Buckets))}
+		pr.histograms[labels] = h
+	}
+	for i, le := range pr.Buckets {
+		if seconds <= le {
+			h.counts[i]++
+		}
+	}
+	h.sum += seconds
+	h.count++
+}
+
+// RecordRequest will count the invocation, cold start, response status class and validation failure,
+// and observe the phase durations of the request.
+func (pr *PrometheusRecorder) RecordRequest(rm RequestMetrics) error {
+	pr.mu.Lock()
+	defer pr.mu.Unlock()
+	if pr.counters == nil {
+		pr.counters = map[string]map[string]float64{}
+		pr.histograms = map[string]*histogram{}
+	}
+	endpoint := promLabels("endpoint", rm.Endpoint)
+	pr.add(promInvocations, endpoint, 1)
+	if rm.ColdStart {
+		pr.add(promColdStarts, endpoint, 1)
+	}
+	pr.add(promResponses, promLabels("endpoint", rm.Endpoint, "status_class", rm.StatusClass()), 1)
+	if rm.ValidationFailure != "" {
+		pr.add(promValidationFailures, promLabels("endpoint", rm.Endpoint, "location", rm.ValidationFailure), 1)
+	}
+	for _, pd := range rm.phaseDurations() {
+		pr.observe(promLabels("endpoint", rm.Endpoint, "phase", pd.phase), pd.duration.Seconds())
+	}
+	return nil
+}
+
+// formatFloat will format a sample value like Prometheus does.
+func formatFloat(f float64) string {
+	return strconv.FormatFloat(f, 'g', -1, 64)
+}
+
+// sortedLabels will return the label sets of the samples in order.
+func sortedLabels(samples map[string]float64) []string {
+	labels := make([]string, 0, len(samples))
+	for l := range samples {
+		labels = append(labels, l)
+	}
+	sort.Strings(labels)
+	return labels
+}
+
+// WriteTo will write the recorded metrics to w in the Prometheus text exposition format.
+func (pr *PrometheusRecorder) WriteTo(w io.Writer) (int64, error) {
+	buf := &bytes.Buffer{}
+	pr.mu.Lock()
+	for _, name := range []string{promInvocations, promColdStarts, promResponses, promValidationFailures} {
+		fmt.Fprintf(buf, "# HELP %v %v\n# TYPE %v counter\n", name, promHelp[name], name)
+		samples := pr.counters[name]
+		for _, labels := range sortedLabels(samples) {
+			fmt.Fprintf(buf, "%v{%v} %v\n", name, labels, formatFloat(samples[labels]))
+		}
+	}
+
+	fmt.Fprintf(buf, "# HELP %v %v\n# TYPE %v histogram\n", promPhaseDuration, promHelp[promPhaseDuration],
+		promPhaseDuration)
+	histogramLabels := make([]string, 0, len(pr.histograms))
+	for labels := range pr.histograms {
+		histogramLabels = append(histogramLabels, labels)
+	}
+	sort.Strings(histogramLabels)
+	for _, labels := range histogramLabels {
+		h := pr.histograms[labels]
+		for i, le := range pr.Buckets {
+			fmt.Fprintf(buf, "%v_bucket{%v,le=\"%v\"} %v\n", promPhaseDuration, labels, formatFloat(le), h.counts[i])
+		}
+		fmt.Fprintf(buf, "%v_bucket{%v,le=\"+Inf\"} %v\n", promPhaseDuration, labels, h.count)
+		fmt.Fprintf(buf, "%v_sum{%v} %v\n", promPhaseDuration, labels, formatFloat(h.sum))
+		fmt.Fprintf(buf, "%v_count{%v} %v\n", promPhaseDuration, labels, h.count)
+	}
+	pr.mu.Unlock()
+
+	n, err := w.Write(buf.Bytes())
+	return int64(n), err
+}
+
+// ServeHTTP will serve the recorded metrics in the Prometheus text exposition format.
+func (pr *PrometheusRecorder) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", PROMETHEUS_CONTENT_TYPE)
+	pr.WriteTo(w)
+}
@@ -0,0 +1,90 @@
+package metrics
+
+import (
+	"bytes"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestPrometheusRecorder(t *testing.T) {
+	recorder := NewPrometheusRecorder()
+	recorder.Buckets = []float64{0.01, 0.1}
+	recorder.RecordRequest(RequestMetrics{
+		Endpoint:           "/user",
+		StatusCode:         200,
+		ColdStart:          true,
+		ValidationDuration: 5 * time.Millisecond,
+		FunctionDuration:   50 * time.Millisecond,
+		ResponseDuration:   time.Millisecond,
+	})
+	recorder.RecordRequest(RequestMetrics{
+		Endpoint:           "/user",
+		StatusCode:         400,
+		ValidationFailure:  "Request Body",
+		ValidationDuration: 20 * time.Millisecond,
+	})
+
+	buf := &bytes.Buffer{}
+	if _, err := recorder.WriteTo(buf); err != nil {
+		t.Fatalf("prometheus recorder write failed: %v", err)
+	}
+	want := `# HELP gomicro_invocations_total Number of endpoint invocations.
+# TYPE gomicro_invocations_total counter
+gomicro_invocations_total{endpoint="/user"} 2
+# HELP gomicro_cold_starts_total Number of endpoint invocations on a cold start.
+# TYPE gomicro_cold_starts_total counter
+gomicro_cold_starts_total{endpoint="/user"} 1
+# HELP gomicro_responses_total Number of endpoint responses by status class.
+# TYPE gomicro_responses_total counter
+gomicro_responses_total{endpoint="/user",status_class="2xx"} 1
+gomicro_responses_total{endpoint="/user",status_class="4xx"} 1
+# HELP gomicro_validation_failures_total Number of request validation failures by parameter location.
+# TYPE gomicro_validation_failures_total counter
+gomicro_validation_failures_total{endpoint="/user",location="Request Body"} 1
+# HELP gomicro_phase_duration_seconds Duration of the endpoint invocation phases.
+# TYPE gomicro_phase_duration_seconds histogram
+gomicro_phase_duration_seconds_bucket{endpoint="/user",phase="function",le="0.01"} 0
+gomicro_phase_duration_seconds_bucket{endpoint="/user",phase="function",le="0.1"} 1
+gomicro_phase_duration_seconds_bucket{endpoint="/user",phase="function",le="+Inf"} 1
+gomicro_phase_duration_seconds_sum{endpoint="/user",phase="function"} 0.05
+gomicro_phase_duration_seconds_count{endpoint="/user",phase="function"} 1
+gomicro_phase_duration_seconds_bucket{endpoint="/user",phase="response",le="0.01"} 1
+gomicro_phase_duration_seconds_bucket{endpoint="/user",phase="response",le="0.1"} 1
+gomicro_phase_duration_seconds_bucket{endpoint="/user",phase="response",le="+Inf"} 1
+gomicro_phase_duration_seconds_sum{endpoint="/user",phase="response"} 0.001
+gomicro_phase_duration_seconds_count{endpoint="/user",phase="response"} 1
+gomicro_phase_duration_seconds_bucket{endpoint="/user",phase="validation",le="0.01"} 1
+gomicro_phase_duration_seconds_bucket{endpoint="/user",phase="validation",le="0.1"} 2
+gomicro_phase_duration_seconds_bucket{endpoint="/user",phase="validation",le="+Inf"} 2
+gomicro_phase_duration_seconds_sum{endpoint="/user",phase="validation"} 0.025
+gomicro_phase_duration_seconds_count{endpoint="/user",phase="validation"} 2
+`
+	if buf.String() != want {
+		t.Errorf("prometheus exposition got\n%v\nwant\n%v", buf.String(), want)
+	}
+}
+
+func TestPrometheusLabelEscaping(t *testing.T) {
+	got := promLabels("endpoint", "/a\"b\\c\nd")
+	want := `endpoint="/a\"b\\c\nd"`
+	if got != want {
+		t.Errorf("labels got %v, want %v", got, want)
+	}
+}
+
+func TestPrometheusRecorderHandler(t *testing.T) {
+	recorder := &PrometheusRecorder{}
+	recorder.RecordRequest(RequestMetrics{Endpoint: "/user", StatusCode: 200})
+
+	w := httptest.NewRecorder()
+	recorder.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/metrics", nil))
+	if w.Header().Get("Content-Type") != PROMETHEUS_CONTENT_TYPE {
+		t.Errorf("content type got %v", w.Header().Get("Content-Type"))
+	}
+	if !strings.Contains(w.Body.String(), `gomicro_invocations_total{endpoint="/user"} 1`) {
+		t.Errorf("zero value recorder did not record the request, got %v", w.Body.String())
+	}
+}
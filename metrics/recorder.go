@@ -0,0 +1,171 @@
+package metrics
+
+import (
+	"context"
+	"strconv"
+	"time"
+)
+
+// Names of the built-in request metrics
+const (
+	INVOCATIONS_METRIC         = "Invocations"
+	COLD_STARTS_METRIC         = "ColdStarts"
+	RESPONSES_METRIC           = "Responses"
+	VALIDATION_FAILURES_METRIC = "ValidationFailures"
+	VALIDATION_DURATION_METRIC = "ValidationDuration"
+	FUNCTION_DURATION_METRIC   = "FunctionDuration"
+	RESPONSE_DURATION_METRIC   = "ResponseDuration"
+)
+
+// Phases of an endpoint invocation timed by the built-in request metrics
+const (
+	VALIDATION_PHASE = "validation"
+	FUNCTION_PHASE   = "function"
+	RESPONSE_PHASE   = "response"
+)
+
+// RequestMetrics are the built-in metrics of a single endpoint invocation. The duration of a phase
+// that was not reached is zero.
+type RequestMetrics struct {
+	Endpoint           string
+	RequestID          string
+	CorrelationID      string
+	StatusCode         int
+	ColdStart          bool
+	ValidationFailure  string
+	ValidationDuration time.Duration
+	FunctionDuration   time.Duration
+	ResponseDuration   time.Duration
+}
+
+// StatusClass will return the class of the status code, e.g. 2xx.
+func (rm RequestMetrics) StatusClass() string {
+	if rm.StatusCode < 100 || rm.StatusCode > 599 {
+		return "unknown"
+	}
+	return strconv.Itoa(rm.StatusCode/100) + "xx"
+}
+
+// phaseDurations will return the durations of the reached phases in order.
+func (rm RequestMetrics) phaseDurations() []phaseDuration {
+	phases := []phaseDuration{}
+	for _, pd := range []phaseDuration{
+		{VALIDATION_PHASE, VALIDATION_DURATION_METRIC, rm.ValidationDuration},
+		{FUNCTION_PHASE, FUNCTION_DURATION_METRIC, rm.FunctionDuration},
+		{RESPONSE_PHASE, RESPONSE_DURATION_METRIC, rm.ResponseDuration},
+	} {
+		if pd.duration > 0 {
+			phases = append(phases, pd)
+		}
+	}
+	return phases
+}
+
+// phaseDuration is the duration of an invocation phase with its EMF metric name
+type phaseDuration struct {
+	phase    string
+	metric   string
+	duration time.Duration
+}
+
+// Recorder records the built-in metrics of the endpoint invocations. Recorders are shared by
+// concurrent invocations and must be safe for concurrent use.
+type Recorder interface {
+	RecordRequest(rm RequestMetrics) error
+}
+
+// EMFRecorder records the request metrics as CloudWatch Embedded Metric Format documents. An empty
+// Namespace or nil Sink defaults to the DefaultNamespace and DefaultSink at record time.
+type EMFRecorder struct {
+	Namespace string
+	Sink      Sink
+}
+
+// DefaultRecorder is the recorder of the endpoints invoked without a recorder in their context.
+var DefaultRecorder Recorder = &EMFRecorder{}
+
+// NewEMFRecorder will create a recorder emitting to the sink under the namespace.
+func NewEMFRecorder(namespace string, sink Sink) *EMFRecorder {
+	return &EMFRecorder{
+		Namespace: namespace,
+		Sink:      sink,
+	}
+}
+
+// newMetricsLogger will create a metrics logger of the request aggregated by the dimensions.
+func (er *EMFRecorder) newMetricsLogger(rm RequestMetrics, dims map[string]string) *MetricsLogger {
+	namespace, sink := er.Namespace, er.Sink
+	if namespace == "" {
+		namespace = DefaultNamespace
+	}
+	if sink == nil {
+		sink = DefaultSink
+	}
+	m := NewMetricsLogger(namespace, sink)
+	m.SetDimensions(dims)
+	if rm.RequestID != "" {
+		m.SetProperty("requestId", rm.RequestID)
+	}
+	if rm.CorrelationID != "" {
+		m.SetProperty("correlationId", rm.CorrelationID)
+	}
+	return m
+}
+
+// RecordRequest will emit the invocation count, cold start and phase durations by endpoint, the
+// response count by endpoint and status class, and the validation failure count by endpoint and
+// parameter location.
+func (er *EMFRecorder) RecordRequest(rm RequestMetrics) error {
+	byEndpoint := er.newMetricsLogger(rm, map[string]string{"Endpoint": rm.Endpoint})
+	byEndpoint.PutMetric(INVOCATIONS_METRIC, 1, COUNT)
+	coldStart := 0.0
+	if rm.ColdStart {
+		coldStart = 1
+	}
+	byEndpoint.PutMetric(COLD_STARTS_METRIC, coldStart, COUNT)
+	for _, pd := range rm.phaseDurations() {
+		byEndpoint.PutMetric(pd.metric, float64(pd.duration)/float64(time.Millisecond), MILLISECONDS)
+	}
+	loggers := []*MetricsLogger{byEndpoint}
+
+	byStatus := er.newMetricsLogger(rm, map[string]string{"Endpoint": rm.Endpoint, "StatusClass": rm.StatusClass()})
+	byStatus.PutMetric(RESPONSES_METRIC, 1, COUNT)
+	loggers = append(loggers, byStatus)
+
+	if rm.ValidationFailure != "" {
+		byLocation := er.newMetricsLogger(rm, map[string]string{
+			"Endpoint":          rm.Endpoint,
+			"ParameterLocation": rm.ValidationFailure,
+		})
+		byLocation.PutMetric(VALIDATION_FAILURES_METRIC, 1, COUNT)
+		loggers = append(loggers, byLocation)
+	}
+
+	for _, m := range loggers {
+		if err := m.Flush(); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// recorderContextKey is the key of the recorder in a context.Context
+type recorderContextKey struct{}
+
+// RecorderIntoContext will return a copy of ctx carrying the recorder.
+func RecorderIntoContext(ctx context.Context, r Recorder) context.Context {
+	if ctx == nil {
+		ctx = context.Background()
+	}
+	return context.WithValue(ctx, recorderContextKey{}, r)
+}
+
+// RecorderFromContext will return the recorder carried by ctx, or the DefaultRecorder without one.
+func RecorderFromContext(ctx context.Context) Recorder {
+	if ctx != nil {
+		if r, ok := ctx.Value(recorderContextKey{}).(Recorder); ok && r != nil {
+			return r
+		}
+	}
+	return DefaultRecorder
+}
@@ -0,0 +1,106 @@
+package metrics
+
+import (
+	"context"
+	"reflect"
+	"testing"
+	"time"
+)
+
+var statusClassTests = []struct {
+	testName   string
+	statusCode int
+	want       string
+}{
+	{"ok", 200, "2xx"},
+	{"bad request", 400, "4xx"},
+	{"internal server error", 500, "5xx"},
+	{"missing status", 0, "unknown"},
+}
+
+func TestStatusClass(t *testing.T) {
+	for _, tt := range statusClassTests {
+		t.Run(tt.testName, func(t *testing.T) {
+			if got := (RequestMetrics{StatusCode: tt.statusCode}).StatusClass(); got != tt.want {
+				t.Errorf("status class got %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestEMFRecorder(t *testing.T) {
+	defer mockNow()()
+	sink := NewMemorySink()
+	recorder := NewEMFRecorder("go-micro/users", sink)
+	err := recorder.RecordRequest(RequestMetrics{
+		Endpoint:           "/user/{userId}",
+		RequestID:          "request-1",
+		StatusCode:         400,
+		ColdStart:          true,
+		ValidationFailure:  "Path Parameter",
+		ValidationDuration: 1500 * time.Microsecond,
+	})
+	if err != nil {
+		t.Fatalf("emf recorder failed: %v", err)
+	}
+
+	docs := sink.Documents()
+	if len(docs) != 3 {
+		t.Fatalf("documents got %v, want 3", len(docs))
+	}
+	wantDimensions := [][]interface{}{
+		{"Endpoint"},
+		{"Endpoint", "StatusClass"},
+		{"Endpoint", "ParameterLocation"},
+	}
+	for i, doc := range docs {
+		directive := doc["_aws"].(map[string]interface{})["CloudWatchMetrics"].([]interface{})[0].(map[string]interface{})
+		if got := directive["Dimensions"].([]interface{})[0]; !reflect.DeepEqual(got, wantDimensions[i]) {
+			t.Errorf("document %v dimensions got %v, want %v", i, got, wantDimensions[i])
+		}
+		if directive["Namespace"] != "go-micro/users" || doc["requestId"] != "request-1" {
+			t.Errorf("document %v namespace or properties not set: %v", i, doc)
+		}
+	}
+	if docs[1]["StatusClass"] != "4xx" || docs[2]["ParameterLocation"] != "Path Parameter" {
+		t.Errorf("dimension values not set: %v", docs)
+	}
+	wantValues := map[string][]float64{
+		INVOCATIONS_METRIC:         {1},
+		COLD_STARTS_METRIC:         {1},
+		RESPONSES_METRIC:           {1},
+		VALIDATION_FAILURES_METRIC: {1},
+		VALIDATION_DURATION_METRIC: {1.5},
+		FUNCTION_DURATION_METRIC:   {},
+	}
+	for name, want := range wantValues {
+		if got := sink.Values(name); !reflect.DeepEqual(got, want) {
+			t.Errorf("metric %v got %v, want %v", name, got, want)
+		}
+	}
+}
+
+func TestEMFRecorderDefaults(t *testing.T) {
+	defaultSink := DefaultSink
+	defer func() { DefaultSink = defaultSink }()
+	sink := NewMemorySink()
+	DefaultSink = sink
+
+	DefaultRecorder.RecordRequest(RequestMetrics{Endpoint: "/user", StatusCode: 200})
+	if got := sink.Values(RESPONSES_METRIC); !reflect.DeepEqual(got, []float64{1}) {
+		t.Errorf("default recorder responses got %v", got)
+	}
+	if len(sink.Documents()) != 2 {
+		t.Errorf("default recorder emitted a validation failure document without a failure")
+	}
+}
+
+func TestRecorderContext(t *testing.T) {
+	recorder := NewPrometheusRecorder()
+	if RecorderFromContext(RecorderIntoContext(context.Background(), recorder)) != recorder {
+		t.Errorf("recorder not carried by the context")
+	}
+	if RecorderFromContext(context.Background()) != DefaultRecorder {
+		t.Errorf("context without recorder does not fall back to the default recorder")
+	}
+}
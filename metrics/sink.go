@@ -0,0 +1,88 @@
+package metrics
+
+import (
+	"encoding/json"
+	"io"
+	"sync"
+)
+
+// Sink is the destination of the EMF documents. Sinks must be safe for concurrent use.
+type Sink interface {
+	WriteMetrics(document []byte) error
+}
+
+// WriterSink writes each EMF document as a line to an io.Writer, e.g. stdout in lambda.
+type WriterSink struct {
+	mu     sync.Mutex
+	Writer io.Writer
+}
+
+// MemorySink keeps the EMF documents in memory. It is meant for tests.
+type MemorySink struct {
+	mu        sync.Mutex
+	documents [][]byte
+}
+
+// NewWriterSink will create a sink writing to w.
+func NewWriterSink(w io.Writer) *WriterSink {
+	return &WriterSink{
+		Writer: w,
+	}
+}
+
+// WriteMetrics will write the document as a line to the writer.
+func (ws *WriterSink) WriteMetrics(document []byte) error {
+	ws.mu.Lock()
+	defer ws.mu.Unlock()
+	_, err := ws.Writer.Write(append(append([]byte{}, document...), '\n'))
+	return err
+}
+
+// NewMemorySink will create an in-memory sink.
+func NewMemorySink() *MemorySink {
+	return &MemorySink{}
+}
+
+// WriteMetrics will keep the document in memory.
+func (ms *MemorySink) WriteMetrics(document []byte) error {
+	ms.mu.Lock()
+	defer ms.mu.Unlock()
+	ms.documents = append(ms.documents, append([]byte{}, document...))
+	return nil
+}
+
+// Documents will return the EMF documents written to the sink in order, decoded as maps.
+func (ms *MemorySink) Documents() []map[string]interface{} {
+	ms.mu.Lock()
+	defer ms.mu.Unlock()
+	docs := make([]map[string]interface{}, 0, len(ms.documents))
+	for _, document := range ms.documents {
+		doc := map[string]interface{}{}
+		json.Unmarshal(document, &doc)
+		docs = append(docs, doc)
+	}
+	return docs
+}
+
+// Values will return all the values of the metric written to the sink in order.
+func (ms *MemorySink) Values(name string) []float64 {
+	values := []float64{}
+	for _, doc := range ms.Documents() {
+		switch v := doc[name].(type) {
+		case float64:
+			values = append(values, v)
+		case []interface{}:
+			for _, value := range v {
+				values = append(values, value.(float64))
+			}
+		}
+	}
+	return values
+}
+
+// Reset will remove all the documents written to the sink.
+func (ms *MemorySink) Reset() {
+	ms.mu.Lock()
+	defer ms.mu.Unlock()
+	ms.documents = nil
+}
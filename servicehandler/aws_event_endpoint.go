@@ -0,0 +1,139 @@
+package servicehandler
+
+import (
+	"context"
+	"fmt"
+	"go-micro/logger"
+	"time"
+
+	"github.com/aws/aws-lambda-go/events"
+)
+
+/* Source and Detail Type of EventBridge scheduled (cron/rate) events */
+const (
+	SCHEDULED_EVENT_SOURCE      = "aws.events"
+	SCHEDULED_EVENT_DETAIL_TYPE = "Scheduled Event"
+)
+
+/* EventBridge/CloudWatch event passed to the event function */
+type EventBridgeEvent struct {
+	ID         string
+	Source     string
+	DetailType string
+	AccountID  string
+	Region     string
+	Time       time.Time
+	Resources  []string
+	Detail     map[string]interface{}
+	Options    interface{}
+}
+
+// EventFunction is the function type of an EventBridge event consumer implementation
+type EventFunction func(ctx context.Context, ee EventBridgeEvent, logger logger.Logger) error
+
+// EventRoute routes an EventBridge event to its event function. An empty Source or DetailType
+// matches any value. The event detail is validated against the RequiredRequestBody of the EventSpec.
+type EventRoute struct {
+	Source     string
+	DetailType string
+	EventSpec  EventSpec
+	Function   EventFunction
+}
+
+type AWSEventEndpoint struct {
+	handler interface{}
+}
+
+// NewScheduledEventRoute will create an EventRoute for EventBridge scheduled (cron/rate) events
+func NewScheduledEventRoute(ef EventFunction) EventRoute {
+	return EventRoute{
+		Source:     SCHEDULED_EVENT_SOURCE,
+		DetailType: SCHEDULED_EVENT_DETAIL_TYPE,
+		Function:   ef,
+	}
+}
+
+// matches will check if the route handles the given event source and detail type
+func (er EventRoute) matches(source string, detailType string) bool {
+	return (er.Source == "" || er.Source == source) && (er.DetailType == "" || er.DetailType == detailType)
+}
+
+// NewEventEndpoint will create the aws EventBridge endpoint instance. Events are dispatched to
+// the first route matching their source and detail type.
+func NewEventEndpoint(routes []EventRoute, lgr logger.Logger, options interface{}) *AWSEventEndpoint {
+	specs := make([]EventSpec, 0, len(routes))
+	for _, route := range routes {
+		specs = append(specs, route.EventSpec)
+	}
+	lgr = redactSensitiveAttributes(lgr, specs...)
+	genericEventEndpoint := func(ctx context.Context, event events.CloudWatchEvent) (reqError error) {
+		// Create the request-scoped logger of the invocation
+		reqLgr := lgr.Derive()
+		reqLgr.RequestID = lambdaRequestID(ctx)
+		ctx = logger.IntoContext(ctx, reqLgr)
+		defer redirectStdLog(reqLgr)()
+
+		// Initialize Event Handler
+		reqLgr.LogTxt(logger.INFO, "Initializing AWS Event Handler..")
+		evh := AWSEventHandler{
+			Logger: reqLgr,
+		}
+
+		// Handle Exceptions
+		defer func() {
+			if err := recover(); err != nil {
+				reqError = evh.HandleExceptions(err)
+			}
+			reqLgr.Flush(reqError != nil)
+		}()
+
+		reqLgr.LogTxt(logger.INFO, "Routing event <"+event.Source+"> <"+event.DetailType+">")
+		var route *EventRoute
+		for i := range routes {
+			if routes[i].matches(event.Source, event.DetailType) {
+				route = &routes[i]
+				break
+			}
+		}
+		if route == nil {
+			reqLgr.LogTxt(logger.ERROR, "No route for event <"+event.Source+"> <"+event.DetailType+">")
+			return fmt.Errorf("no route for event source %v, detail type %v", event.Source, event.DetailType)
+		}
+
+		detail := evh.ParsePayload(EVENT_DETAIL, event.Detail)
+		evh.ValidatePayload(event.Source, EVENT_DETAIL, route.EventSpec.RequiredRequestBody, detail)
+
+		// Execute the event function
+		reqLgr.LogTxt(logger.INFO, "Executing Event Function..")
+		err := route.Function(ctx, EventBridgeEvent{
+			ID:         event.ID,
+			Source:     event.Source,
+			DetailType: event.DetailType,
+			AccountID:  event.AccountID,
+			Region:     event.Region,
+			Time:       event.Time,
+			Resources:  event.Resources,
+			Detail:     detail,
+			Options:    options,
+		}, reqLgr)
+		if err != nil {
+			reqLgr.LogTxt(logger.ERROR, "Event Function failed. "+err.Error())
+		}
+		return err
+	}
+
+	return &AWSEventEndpoint{
+		handler: genericEventEndpoint,
+	}
+}
+
+// Execute will trigger the execution of aws lambda
+func (ae AWSEventEndpoint) Execute() {
+	awsLambdaStart(ae.handler)
+}
+
+// Dryrun will run the event handler without invoking the awslambda
+func (ae AWSEventEndpoint) Dryrun(ctx context.Context, event events.CloudWatchEvent) error {
+	f := ae.handler.(func(context.Context, events.CloudWatchEvent) error)
+	return f(ctx, event)
+}
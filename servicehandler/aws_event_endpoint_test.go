@@ -0,0 +1,165 @@
+package servicehandler
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"go-micro/logger"
+	"testing"
+
+	"github.com/aws/aws-lambda-go/events"
+)
+
+// Testing constants
+const (
+	TEST_EVENT_SOURCE        = "com.mycompany.users"
+	TEST_EVENT_DETAIL_TYPE   = "UserCreated"
+	TEST_EVENT_ROUTE_CREATED = "created"
+	TEST_EVENT_ROUTE_SCHED   = "scheduled"
+	TEST_EVENT_ROUTE_ANY     = "any"
+)
+
+// newEventTestRoutes will create the event routes used in testing. Each route records its name on dispatch.
+func newEventTestRoutes(routed *string) []EventRoute {
+	routeFunction := func(name string) EventFunction {
+		return func(ctx context.Context, ee EventBridgeEvent, logger logger.Logger) error {
+			*routed = name
+			if ee.Detail["fail"] == true {
+				return errors.New("event function failed")
+			}
+			return nil
+		}
+	}
+	return []EventRoute{
+		{
+			Source:     TEST_EVENT_SOURCE,
+			DetailType: TEST_EVENT_DETAIL_TYPE,
+			EventSpec: EventSpec{
+				RequiredRequestBody: ReqEventSpec{
+					ReqEventAttributes: map[string]interface{}{
+						"userId": NewReqEvenAttrib("string", true, 4, 50),
+					},
+				},
+			},
+			Function: routeFunction(TEST_EVENT_ROUTE_CREATED),
+		},
+		NewScheduledEventRoute(routeFunction(TEST_EVENT_ROUTE_SCHED)),
+		{
+			Source:   TEST_EVENT_SOURCE,
+			Function: routeFunction(TEST_EVENT_ROUTE_ANY),
+		},
+	}
+}
+
+// eventEndpointTests for table testing of event endpoint
+var eventEndpointTests = []struct {
+	testName   string
+	source     string
+	detailType string
+	detail     string
+	wantRoute  string
+	wantError  bool
+}{
+	{
+		"test event endpoint valid event",
+		TEST_EVENT_SOURCE,
+		TEST_EVENT_DETAIL_TYPE,
+		`{"userId": "user-1234"}`,
+		TEST_EVENT_ROUTE_CREATED,
+		false,
+	},
+	{
+		"test event endpoint scheduled event",
+		SCHEDULED_EVENT_SOURCE,
+		SCHEDULED_EVENT_DETAIL_TYPE,
+		`{}`,
+		TEST_EVENT_ROUTE_SCHED,
+		false,
+	},
+	{
+		"test event endpoint wildcard detail type",
+		TEST_EVENT_SOURCE,
+		"UserDeleted",
+		``,
+		TEST_EVENT_ROUTE_ANY,
+		false,
+	},
+	{
+		"test event endpoint invalid detail",
+		TEST_EVENT_SOURCE,
+		TEST_EVENT_DETAIL_TYPE,
+		`{"userId": 1}`,
+		"",
+		true,
+	},
+	{
+		"test event endpoint malformed detail",
+		TEST_EVENT_SOURCE,
+		TEST_EVENT_DETAIL_TYPE,
+		`[1, 2]`,
+		"",
+		true,
+	},
+	{
+		"test event endpoint unrouted event",
+		"com.mycompany.orders",
+		"OrderCreated",
+		`{}`,
+		"",
+		true,
+	},
+	{
+		"test event endpoint function error",
+		SCHEDULED_EVENT_SOURCE,
+		SCHEDULED_EVENT_DETAIL_TYPE,
+		`{"fail": true}`,
+		TEST_EVENT_ROUTE_SCHED,
+		true,
+	},
+}
+
+func TestEventEndpoint(t *testing.T) {
+	for _, tt := range eventEndpointTests {
+		t.Run(tt.testName, func(t *testing.T) {
+			routed := ""
+			testEventEndpoint := NewEventEndpoint(newEventTestRoutes(&routed), logger.NewLogger(), nil)
+			err := testEventEndpoint.Dryrun(context.Background(), events.CloudWatchEvent{
+				Source:     tt.source,
+				DetailType: tt.detailType,
+				Detail:     json.RawMessage(tt.detail),
+			})
+			if routed != tt.wantRoute {
+				t.Errorf("event routed to %v, want %v", routed, tt.wantRoute)
+			}
+			if (err != nil) != tt.wantError {
+				t.Errorf("event endpoint error %v, want error %v", err, tt.wantError)
+			}
+		})
+	}
+}
+
+func TestEventEndpointPanic(t *testing.T) {
+	testEventEndpoint := NewEventEndpoint([]EventRoute{
+		NewScheduledEventRoute(func(ctx context.Context, ee EventBridgeEvent, logger logger.Logger) error {
+			panic("unexpected error")
+		}),
+	}, logger.NewLogger(), nil)
+	err := testEventEndpoint.Dryrun(context.Background(), events.CloudWatchEvent{
+		Source:     SCHEDULED_EVENT_SOURCE,
+		DetailType: SCHEDULED_EVENT_DETAIL_TYPE,
+	})
+	if err == nil {
+		t.Errorf("event function panic not converted to error")
+	}
+}
+
+func TestEventEndpointExecute(t *testing.T) {
+	executed := false
+	awsLambdaStart = func(handler interface{}) {
+		_, executed = handler.(func(context.Context, events.CloudWatchEvent) error)
+	}
+	NewEventEndpoint([]EventRoute{}, logger.NewLogger(), nil).Execute()
+	if !executed {
+		t.Errorf("event endpoint handler not started")
+	}
+}
@@ -0,0 +1,58 @@
+package servicehandler
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"go-micro/logger"
+)
+
+// AWSEventHandler is the shared handler of the non http aws event endpoints
+type AWSEventHandler struct {
+	Logger logger.Logger
+}
+
+// UnmarshalPayload will parse a JSON event payload into v.
+// It will raise a bad request http exception if the payload can't be parsed.
+func (eh AWSEventHandler) UnmarshalPayload(paramType int, payload []byte, v interface{}) {
+	if err := json.Unmarshal(payload, v); err != nil {
+		eh.Logger.LogTxt(logger.ERROR, "Invalid "+parameterMap[paramType]+", "+err.Error())
+		causePanic(paramType, INVALID_ATTRIBUTE_TYPE_ERROR, err.Error())
+	}
+}
+
+// ParsePayload will convert a JSON event payload to map. An empty payload is parsed as an empty map.
+// It will raise a bad request http exception if the payload is not a JSON object.
+func (eh AWSEventHandler) ParsePayload(paramType int, payload []byte) map[string]interface{} {
+	parsedPayload := map[string]interface{}{}
+	if len(payload) == 0 || string(payload) == "null" {
+		return parsedPayload
+	}
+	eh.UnmarshalPayload(paramType, payload, &parsedPayload)
+	return parsedPayload
+}
+
+// ValidatePayload will check the event payload against the required event specification.
+// It will raise a bad request http exception if the payload does not match the specification.
+func (eh AWSEventHandler) ValidatePayload(source string, paramType int, res ReqEventSpec,
+	payload map[string]interface{}) {
+	eh.Logger.LogObj(logger.INFO, "Parsing "+parameterMap[paramType], payload, "", false)
+	parseCode, errMsg := recursiveAttributeCheck(source, res, payload, 0)
+	if parseCode != ATTRIBUTE_OK {
+		eh.Logger.LogTxt(logger.ERROR, "Invalid "+parameterMap[paramType]+", "+errMsg)
+		causePanic(paramType, parseCode, errMsg)
+	}
+}
+
+// HandleExceptions will log the recovered panic payload and convert it to an error
+// that is returned to the lambda runtime.
+func (eh AWSEventHandler) HandleExceptions(recoverPayload interface{}) error {
+	if recoverPayload == nil {
+		return nil
+	}
+	logRecoverPayload(eh.Logger, recoverPayload)
+	if ex, ok := recoverPayload.(HTTPException); ok {
+		return errors.New(ex.ErrorMessage)
+	}
+	return fmt.Errorf("Internal Server Error. %v", recoverPayload)
+}
@@ -0,0 +1,238 @@
+package servicehandler
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"go-micro/logger"
+	"strconv"
+	"time"
+
+	"github.com/aws/aws-lambda-go/events"
+)
+
+/* S3 object notification passed to the object function */
+type S3Notification struct {
+	EventName string
+	EventTime time.Time
+	Region    string
+	Bucket    string
+	Key       string
+	Size      int64
+	ETag      string
+	VersionID string
+	Options   interface{}
+}
+
+/* SNS message passed to the message function */
+type SNSNotification struct {
+	MessageID         string
+	TopicArn          string
+	Subject           string
+	Message           string
+	Timestamp         time.Time
+	Body              map[string]interface{}
+	MessageAttributes map[string]interface{}
+	Options           interface{}
+}
+
+// S3Function is the function type of an S3 object notification consumer implementation
+type S3Function func(ctx context.Context, sn S3Notification, logger logger.Logger) error
+
+// SNSFunction is the function type of an SNS message consumer implementation
+type SNSFunction func(ctx context.Context, sn SNSNotification, logger logger.Logger) error
+
+type AWSS3Endpoint struct {
+	handler interface{}
+}
+
+type AWSSNSEndpoint struct {
+	handler interface{}
+}
+
+// processNotification will run the notification function with the exception handling of the endpoints.
+// Panics raised by the function are recovered and returned as error.
+func processNotification(evh AWSEventHandler, process func() error) (notificationError error) {
+	defer func() {
+		if err := recover(); err != nil {
+			notificationError = evh.HandleExceptions(err)
+		}
+	}()
+	if err := process(); err != nil {
+		evh.Logger.LogTxt(logger.ERROR, "Notification Function failed. "+err.Error())
+		return err
+	}
+	return nil
+}
+
+// processS3Records will hand each S3 record to the S3 function in order, stopping at the first failure.
+// It is run through processNotification by the endpoints.
+func processS3Records(ctx context.Context, evh AWSEventHandler, sf S3Function, records []events.S3EventRecord,
+	options interface{}) error {
+	for _, r := range records {
+		sn := S3Notification{
+			EventName: r.EventName,
+			EventTime: r.EventTime,
+			Region:    r.AWSRegion,
+			Bucket:    r.S3.Bucket.Name,
+			Key:       r.S3.Object.URLDecodedKey,
+			Size:      r.S3.Object.Size,
+			ETag:      r.S3.Object.ETag,
+			VersionID: r.S3.Object.VersionID,
+			Options:   options,
+		}
+		evh.Logger.LogTxt(
+			logger.INFO,
+			"Processing S3 Object <"+sn.Bucket+"/"+sn.Key+">. Size <"+strconv.FormatInt(sn.Size, 10)+">",
+		)
+		if err := sf(ctx, sn, evh.Logger); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// snsMessageAttributesToMap will extract the values of SNS message attributes.
+// Number attributes are converted to float64 the same way JSON numbers are parsed.
+func snsMessageAttributesToMap(messageAttributes map[string]interface{}) map[string]interface{} {
+	ret := make(map[string]interface{}, len(messageAttributes))
+	for k, v := range messageAttributes {
+		attribute, ok := v.(map[string]interface{})
+		if !ok {
+			ret[k] = v
+			continue
+		}
+		value := attribute["Value"]
+		switch attribute["Type"] {
+		case "Number":
+			if number, err := strconv.ParseFloat(fmt.Sprint(value), 64); err == nil {
+				value = number
+			}
+		case "String.Array":
+			var list []interface{}
+			if err := json.Unmarshal([]byte(fmt.Sprint(value)), &list); err == nil {
+				value = list
+			}
+		}
+		ret[k] = value
+	}
+	return ret
+}
+
+// NewS3Endpoint will create the aws S3 notification endpoint instance
+func NewS3Endpoint(sf S3Function, lgr logger.Logger, options interface{}) *AWSS3Endpoint {
+	genericS3Endpoint := func(ctx context.Context, event events.S3Event) (reqError error) {
+		// Create the request-scoped logger of the invocation
+		reqLgr := lgr.Derive()
+		reqLgr.RequestID = lambdaRequestID(ctx)
+		ctx = logger.IntoContext(ctx, reqLgr)
+		defer redirectStdLog(reqLgr)()
+
+		reqLgr.LogTxt(logger.INFO, "Initializing AWS S3 Handler..")
+		evh := AWSEventHandler{
+			Logger: reqLgr,
+		}
+		defer func() {
+			reqLgr.Flush(reqError != nil)
+		}()
+		return processNotification(evh, func() error {
+			return processS3Records(ctx, evh, sf, event.Records, options)
+		})
+	}
+
+	return &AWSS3Endpoint{
+		handler: genericS3Endpoint,
+	}
+}
+
+// newSNSEndpoint will create the aws SNS endpoint instance that hands each message to process
+func newSNSEndpoint(lgr logger.Logger, process func(ctx context.Context, evh AWSEventHandler,
+	r events.SNSEventRecord) error) *AWSSNSEndpoint {
+	genericSNSEndpoint := func(ctx context.Context, event events.SNSEvent) (reqError error) {
+		// Create the request-scoped logger of the invocation
+		reqLgr := lgr.Derive()
+		reqLgr.RequestID = lambdaRequestID(ctx)
+		ctx = logger.IntoContext(ctx, reqLgr)
+		defer redirectStdLog(reqLgr)()
+
+		reqLgr.LogTxt(logger.INFO, "Initializing AWS SNS Handler..")
+		evh := AWSEventHandler{
+			Logger: reqLgr,
+		}
+		defer func() {
+			reqLgr.Flush(reqError != nil)
+		}()
+		for _, r := range event.Records {
+			reqLgr.LogTxt(logger.INFO, "Processing SNS Message <"+r.SNS.MessageID+"> from <"+r.SNS.TopicArn+">")
+			if err := processNotification(evh, func() error { return process(ctx, evh, r) }); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+
+	return &AWSSNSEndpoint{
+		handler: genericSNSEndpoint,
+	}
+}
+
+// NewSNSEndpoint will create the aws SNS endpoint instance. Message attributes are validated against
+// the RequiredMessageAttributes of the EventSpec. When the RequiredRequestBody has attributes, the message
+// is parsed as JSON into the notification Body and validated against it.
+func NewSNSEndpoint(es EventSpec, sf SNSFunction, lgr logger.Logger, options interface{}) *AWSSNSEndpoint {
+	lgr = redactSensitiveAttributes(lgr, es)
+	return newSNSEndpoint(lgr, func(ctx context.Context, evh AWSEventHandler, r events.SNSEventRecord) error {
+		sn := SNSNotification{
+			MessageID:         r.SNS.MessageID,
+			TopicArn:          r.SNS.TopicArn,
+			Subject:           r.SNS.Subject,
+			Message:           r.SNS.Message,
+			Timestamp:         r.SNS.Timestamp,
+			MessageAttributes: snsMessageAttributesToMap(r.SNS.MessageAttributes),
+			Options:           options,
+		}
+		evh.ValidatePayload(r.SNS.TopicArn, MESSAGE_ATTRS, es.RequiredMessageAttributes, sn.MessageAttributes)
+		if len(es.RequiredRequestBody.ReqEventAttributes) > 0 {
+			sn.Body = evh.ParsePayload(MESSAGE_BODY, []byte(r.SNS.Message))
+			evh.ValidatePayload(r.SNS.TopicArn, MESSAGE_BODY, es.RequiredRequestBody, sn.Body)
+		}
+		return sf(ctx, sn, evh.Logger)
+	})
+}
+
+// NewSNSS3Endpoint will create the aws SNS endpoint instance for S3 notifications published to an SNS topic.
+// Each SNS message is unwrapped and its S3 records are handed to the S3 function.
+func NewSNSS3Endpoint(sf S3Function, lgr logger.Logger, options interface{}) *AWSSNSEndpoint {
+	return newSNSEndpoint(lgr, func(ctx context.Context, evh AWSEventHandler, r events.SNSEventRecord) error {
+		var s3Event events.S3Event
+		evh.UnmarshalPayload(MESSAGE_BODY, []byte(r.SNS.Message), &s3Event)
+		if len(s3Event.Records) == 0 {
+			// S3 publishes a test event without records when the notification is configured
+			evh.Logger.LogTxt(logger.WARN, "SNS Message has no S3 records, skipping")
+			return nil
+		}
+		return processS3Records(ctx, evh, sf, s3Event.Records, options)
+	})
+}
+
+// Execute will trigger the execution of aws lambda
+func (ae AWSS3Endpoint) Execute() {
+	awsLambdaStart(ae.handler)
+}
+
+// Dryrun will run the S3 handler without invoking the awslambda
+func (ae AWSS3Endpoint) Dryrun(ctx context.Context, event events.S3Event) error {
+	f := ae.handler.(func(context.Context, events.S3Event) error)
+	return f(ctx, event)
+}
+
+// Execute will trigger the execution of aws lambda
+func (ae AWSSNSEndpoint) Execute() {
+	awsLambdaStart(ae.handler)
+}
+
+// Dryrun will run the SNS handler without invoking the awslambda
+func (ae AWSSNSEndpoint) Dryrun(ctx context.Context, event events.SNSEvent) error {
+	f := ae.handler.(func(context.Context, events.SNSEvent) error)
+	return f(ctx, event)
+}
@@ -0,0 +1,250 @@
+package servicehandler
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"go-micro/logger"
+	"go-micro/logger/logtest"
+	"reflect"
+	"testing"
+
+	"github.com/aws/aws-lambda-go/events"
+)
+
+// newS3MockRecord will create an S3 notification record for the given object
+func newS3MockRecord(bucket string, key string, size int64) events.S3EventRecord {
+	return events.S3EventRecord{
+		EventName: "ObjectCreated:Put",
+		AWSRegion: "ap-southeast-1",
+		S3: events.S3Entity{
+			Bucket: events.S3Bucket{Name: bucket},
+			Object: events.S3Object{Key: key, URLDecodedKey: key, Size: size},
+		},
+	}
+}
+
+// newSNSMockRecord will create an SNS record with the given message and message attributes
+func newSNSMockRecord(message string, messageAttributes map[string]interface{}) events.SNSEventRecord {
+	return events.SNSEventRecord{
+		EventSource: "aws:sns",
+		SNS: events.SNSEntity{
+			MessageID:         "message-1",
+			TopicArn:          "arn:aws:sns:ap-southeast-1:123456789012:users",
+			Message:           message,
+			MessageAttributes: messageAttributes,
+		},
+	}
+}
+
+// newS3TestFunction will create an S3 function that records the processed objects
+func newS3TestFunction(processed *[]string) S3Function {
+	return func(ctx context.Context, sn S3Notification, logger logger.Logger) error {
+		*processed = append(*processed, sn.Bucket+"/"+sn.Key)
+		if sn.Size == 0 {
+			return errors.New("empty object")
+		}
+		return nil
+	}
+}
+
+var s3EndpointTests = []struct {
+	testName      string
+	records       []events.S3EventRecord
+	wantProcessed []string
+	wantError     bool
+}{
+	{
+		"test s3 endpoint valid records",
+		[]events.S3EventRecord{
+			newS3MockRecord("uploads", "users/avatar 1.png", 1024),
+			newS3MockRecord("uploads", "users/avatar 2.png", 2048),
+		},
+		[]string{"uploads/users/avatar 1.png", "uploads/users/avatar 2.png"},
+		false,
+	},
+	{
+		"test s3 endpoint function error",
+		[]events.S3EventRecord{
+			newS3MockRecord("uploads", "empty.png", 0),
+			newS3MockRecord("uploads", "avatar.png", 1024),
+		},
+		[]string{"uploads/empty.png"},
+		true,
+	},
+}
+
+func TestS3Endpoint(t *testing.T) {
+	for _, tt := range s3EndpointTests {
+		t.Run(tt.testName, func(t *testing.T) {
+			processed := []string{}
+			testEndpoint := NewS3Endpoint(newS3TestFunction(&processed), logger.NewLogger(), nil)
+			err := testEndpoint.Dryrun(context.Background(), events.S3Event{Records: tt.records})
+			if !reflect.DeepEqual(processed, tt.wantProcessed) {
+				t.Errorf("processed objects %v, want %v", processed, tt.wantProcessed)
+			}
+			if (err != nil) != tt.wantError {
+				t.Errorf("s3 endpoint error %v, want error %v", err, tt.wantError)
+			}
+		})
+	}
+}
+
+var snsEndpointTests = []struct {
+	testName       string
+	record         events.SNSEventRecord
+	wantAttributes map[string]interface{}
+	wantBody       map[string]interface{}
+	wantError      bool
+}{
+	{
+		"test sns endpoint valid message",
+		newSNSMockRecord(`{"userId": "user-1234"}`, map[string]interface{}{
+			"eventType": map[string]interface{}{"Type": "String", "Value": "UserCreated"},
+			"version":   map[string]interface{}{"Type": "Number", "Value": "2"},
+			"tags":      map[string]interface{}{"Type": "String.Array", "Value": `["a", "b"]`},
+		}),
+		map[string]interface{}{
+			"eventType": "UserCreated",
+			"version":   float64(2),
+			"tags":      []interface{}{"a", "b"},
+		},
+		map[string]interface{}{"userId": "user-1234"},
+		false,
+	},
+	{
+		"test sns endpoint missing message attribute",
+		newSNSMockRecord(`{"userId": "user-1234"}`, map[string]interface{}{}),
+		nil,
+		nil,
+		true,
+	},
+	{
+		"test sns endpoint invalid message body",
+		newSNSMockRecord(`{"userId": 1234}`, map[string]interface{}{
+			"eventType": map[string]interface{}{"Type": "String", "Value": "UserCreated"},
+		}),
+		nil,
+		nil,
+		true,
+	},
+	{
+		"test sns endpoint malformed message body",
+		newSNSMockRecord(`user created`, map[string]interface{}{
+			"eventType": map[string]interface{}{"Type": "String", "Value": "UserCreated"},
+		}),
+		nil,
+		nil,
+		true,
+	},
+}
+
+func TestSNSEndpoint(t *testing.T) {
+	eventSpec := EventSpec{
+		RequiredRequestBody: ReqEventSpec{
+			ReqEventAttributes: map[string]interface{}{
+				"userId": NewReqEvenAttrib("string", true, 4, 50),
+			},
+		},
+		RequiredMessageAttributes: ReqEventSpec{
+			ReqEventAttributes: map[string]interface{}{
+				"eventType": NewReqEvenAttrib("string", true, 4, 50),
+			},
+		},
+	}
+	for _, tt := range snsEndpointTests {
+		t.Run(tt.testName, func(t *testing.T) {
+			var got *SNSNotification
+			testEndpoint := NewSNSEndpoint(eventSpec, func(ctx context.Context, sn SNSNotification,
+				logger logger.Logger) error {
+				got = &sn
+				return nil
+			}, logger.NewLogger(), nil)
+			err := testEndpoint.Dryrun(context.Background(), events.SNSEvent{
+				Records: []events.SNSEventRecord{tt.record},
+			})
+			if (err != nil) != tt.wantError {
+				t.Errorf("sns endpoint error %v, want error %v", err, tt.wantError)
+			}
+			if tt.wantError {
+				if got != nil {
+					t.Errorf("sns function executed on invalid message")
+				}
+				return
+			}
+			if !reflect.DeepEqual(got.MessageAttributes, tt.wantAttributes) {
+				t.Errorf("message attributes %v, want %v", got.MessageAttributes, tt.wantAttributes)
+			}
+			if !reflect.DeepEqual(got.Body, tt.wantBody) {
+				t.Errorf("message body %v, want %v", got.Body, tt.wantBody)
+			}
+		})
+	}
+}
+
+func TestSNSS3Endpoint(t *testing.T) {
+	s3Message, _ := json.Marshal(events.S3Event{
+		Records: []events.S3EventRecord{
+			newS3MockRecord("uploads", "users/avatar+1.png", 1024),
+		},
+	})
+	var snsS3EndpointTests = []struct {
+		testName      string
+		message       string
+		wantProcessed []string
+		wantError     bool
+		wantSkipped   bool
+	}{
+		{"test sns wrapped s3 notification", string(s3Message), []string{"uploads/users/avatar 1.png"}, false, false},
+		{"test sns wrapped s3 test event", `{"Service": "Amazon S3", "Event": "s3:TestEvent"}`, []string{}, false, true},
+		{"test sns wrapped invalid message", `not json`, []string{}, true, false},
+	}
+	for _, tt := range snsS3EndpointTests {
+		t.Run(tt.testName, func(t *testing.T) {
+			processed := []string{}
+			rec := logtest.New()
+			testEndpoint := NewSNSS3Endpoint(newS3TestFunction(&processed), rec.Logger, nil)
+			err := testEndpoint.Dryrun(context.Background(), events.SNSEvent{
+				Records: []events.SNSEventRecord{newSNSMockRecord(tt.message, nil)},
+			})
+			if !reflect.DeepEqual(processed, tt.wantProcessed) {
+				t.Errorf("processed objects %v, want %v", processed, tt.wantProcessed)
+			}
+			if (err != nil) != tt.wantError {
+				t.Errorf("sns s3 endpoint error %v, want error %v", err, tt.wantError)
+			}
+			if tt.wantSkipped {
+				rec.AssertLogged(t, logtest.Level(logger.WARN), logtest.Text("no S3 records"))
+			} else {
+				rec.AssertNotLogged(t, logtest.Level(logger.WARN))
+			}
+		})
+	}
+}
+
+func TestNotificationEndpointPanic(t *testing.T) {
+	testEndpoint := NewS3Endpoint(func(ctx context.Context, sn S3Notification, logger logger.Logger) error {
+		panic("unexpected error")
+	}, logger.NewLogger(), nil)
+	err := testEndpoint.Dryrun(context.Background(), events.S3Event{
+		Records: []events.S3EventRecord{newS3MockRecord("uploads", "avatar.png", 1024)},
+	})
+	if err == nil {
+		t.Errorf("s3 function panic not converted to error")
+	}
+}
+
+func TestNotificationEndpointExecute(t *testing.T) {
+	started := []bool{}
+	awsLambdaStart = func(handler interface{}) {
+		_, isS3 := handler.(func(context.Context, events.S3Event) error)
+		_, isSNS := handler.(func(context.Context, events.SNSEvent) error)
+		started = append(started, isS3 || isSNS)
+	}
+	NewS3Endpoint(nil, logger.NewLogger(), nil).Execute()
+	NewSNSEndpoint(EventSpec{}, nil, logger.NewLogger(), nil).Execute()
+	NewSNSS3Endpoint(nil, logger.NewLogger(), nil).Execute()
+	if !reflect.DeepEqual(started, []bool{true, true, true}) {
+		t.Errorf("notification endpoint handlers not started")
+	}
+}
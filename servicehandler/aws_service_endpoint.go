@@ -6,15 +6,13 @@ import (
 
 	"github.com/aws/aws-lambda-go/events"
 	"github.com/aws/aws-lambda-go/lambda"
+	"go.opentelemetry.io/otel/attribute"
 )
 
 type AWSServiceEndpoint struct {
 	handler interface{}
 }
 
-// ServiceFunction is the function type of microservice funtion implementation
-type ServiceFunction func(ctx context.Context, se ServiceEvent, logger logger.Logger) string
-
 // awsLambdaStart is the trigger for lambda execution that can me mocked in testing
 var awsLambdaStart = func(handler interface{}) {
 	lambda.Start(handler)
@@ -23,49 +21,61 @@ var awsLambdaStart = func(handler interface{}) {
 // NewServiceEndpoint will create the aws service enpoint instance
 func NewServiceEndpoint(es EventSpec, sf ServiceFunction, lgr logger.Logger,
 	retHeaders map[string]string, options interface{}) *AWSServiceEndpoint {
-	defaultRetHeaders := map[string]string{
-		"Content-Type": "application/json",
-	}
-
+	lgr = redactSensitiveAttributes(lgr, es)
 	genericServiceEnpoint := func(ctx context.Context,
 		event events.APIGatewayProxyRequest) (response events.APIGatewayProxyResponse, reqError error) {
-		// Append Return Headers
+		// Create the request-scoped logger of the invocation
+		reqLgr := lgr.Derive()
+		reqLgr.RequestID = lambdaRequestID(ctx)
+		if reqLgr.RequestID == "" {
+			reqLgr.RequestID = event.RequestContext.RequestID
+		}
+		header := func(key string) string {
+			return headerValue(event.Headers, key)
+		}
+		reqLgr.CorrelationID = requestCorrelationID(header)
+		overrideLogLevel(&reqLgr, header)
+
+		// Trace the invocation as part of the trace of the caller
+		endpoint := endpointName(event.Resource, event.RequestContext.ResourcePath)
+		ctx, span := startInvocationSpan(ctx, &reqLgr, header)
+		defer endInvocationSpan(ctx, span)
+		defer redirectStdLog(reqLgr)()
+		span.SetAttributes(
+			attribute.String("http.method", event.HTTPMethod),
+			attribute.String("http.route", endpoint),
+			attribute.String("faas.execution", reqLgr.RequestID),
+			attribute.String("correlation.id", reqLgr.CorrelationID),
+		)
+
+		// Assemble the Return Headers of the invocation
+		reqRetHeaders := map[string]string{
+			"Content-Type": "application/json",
+		}
 		for k, v := range retHeaders {
-			defaultRetHeaders[k] = v
+			reqRetHeaders[k] = v
 		}
+		reqRetHeaders[CORRELATION_ID_HEADER] = reqLgr.CorrelationID
 
 		// Initialize Service Handler
-		lgr.LogTxt(logger.INFO, "Initializing AWS Service Handler..")
+		reqLgr.LogTxt(logger.INFO, "Initializing AWS Service Handler..")
 		svh := AWSServiceHandler{
-			Event:  event,
-			Logger: lgr,
+			Event:   event,
+			Logger:  reqLgr,
+			Context: ctx,
 		}
 
-		// Handle Http Exceptions
-		defer func() {
-			err := recover()
-			if err != nil {
-				response = svh.HandleExceptions(
-					err,
-					defaultRetHeaders,
-				).(events.APIGatewayProxyResponse)
-			}
-			lgr.DisplayLogsBackward()
-		}()
-
-		se := svh.NewServiceEvent(es, options)
-
-		// Execute the service function
-		lgr.LogTxt(logger.INFO, "Executing Service Function..")
-		responseBody := sf(ctx, se, lgr)
-
-		// Generate New HTTP Response
-		lgr.LogTxt(logger.INFO, "Building Response..")
-		response = svh.NewHTTPResponse(ServiceResponse{
-			StatusCode:    200,
-			ReturnBody:    responseBody,
-			ReturnHeaders: defaultRetHeaders,
-		}).(events.APIGatewayProxyResponse)
+		response = executeServiceFunction(
+			ctx,
+			svh,
+			reqLgr,
+			endpoint,
+			es,
+			sf,
+			reqRetHeaders,
+			options,
+		).(events.APIGatewayProxyResponse)
+		span.SetAttributes(attribute.Int("http.status_code", response.StatusCode))
 
 		return response, nil
 	}
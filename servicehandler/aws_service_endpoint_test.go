@@ -4,9 +4,24 @@ import (
 	"context"
 	"fmt"
 	"go-micro/logger"
+	"go-micro/metrics"
+	"go-micro/tracing"
+	"log"
+	"os"
+	"reflect"
+	"regexp"
+	"strings"
+	"sync"
+	"sync/atomic"
 	"testing"
 
 	"github.com/aws/aws-lambda-go/events"
+	"github.com/aws/aws-lambda-go/lambdacontext"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+	"go.opentelemetry.io/otel/trace"
 )
 
 // Testing constants
@@ -102,3 +117,692 @@ func TestServiceValidRequest(t *testing.T) {
 		})
 	}
 }
+
+func TestServiceEndpointLoggerIsolation(t *testing.T) {
+	lgr := logger.NewLogger()
+	invocationLoggers := []logger.Logger{}
+	testServiceEndpoint := NewServiceEndpoint(
+		EventSpec{},
+		func(ctx context.Context, se ServiceEvent, lgr logger.Logger) string {
+			invocationLoggers = append(invocationLoggers, lgr)
+			return TEST_AWS_RESPONSE_OK
+		},
+		lgr,
+		map[string]string{},
+		nil,
+	)
+	testServiceEndpoint.Dryrun(context.Background(), events.APIGatewayProxyRequest{})
+	testServiceEndpoint.Dryrun(context.Background(), events.APIGatewayProxyRequest{})
+
+	if lgr.LogHistory.Len() != 0 {
+		t.Errorf("invocation logs recorded in the base logger")
+	}
+	first, second := invocationLoggers[0].LogHistory, invocationLoggers[1].LogHistory
+	if first == second || first.Len() == 0 || first.Len() != second.Len() {
+		t.Errorf("invocation logs not isolated per invocation, got %v and %v logs", first.Len(), second.Len())
+	}
+}
+
+func TestServiceEndpointConcurrentInvocations(t *testing.T) {
+	const invocations = 50
+	testServiceEndpoint := NewServiceEndpoint(
+		EventSpec{
+			RequiredQueryParams: ReqEventSpec{
+				ReqEventAttributes: map[string]interface{}{
+					"testQparam": NewReqEvenAttrib("string", true, 4, 50),
+				},
+			},
+		},
+		func(ctx context.Context, se ServiceEvent, lgr logger.Logger) string {
+			// Service functions logging from their own goroutines
+			var wg sync.WaitGroup
+			for i := 0; i < 5; i++ {
+				wg.Add(1)
+				go func() {
+					defer wg.Done()
+					lgr.LogTxt(logger.INFO, "Test concurrent service function log")
+				}()
+			}
+			wg.Wait()
+			return TEST_AWS_RESPONSE_OK
+		},
+		logger.NewLogger(),
+		map[string]string{TEST_EXTRA_HEADER_KEY: TEST_EXTRA_HEADER_VALUE},
+		nil,
+	)
+
+	var wg sync.WaitGroup
+	for i := 0; i < invocations; i++ {
+		wg.Add(1)
+		go func(isValid bool) {
+			defer wg.Done()
+			request := events.APIGatewayProxyRequest{}
+			want := TEST_ERROR_CONTENT_TYPE
+			if isValid {
+				request.QueryStringParameters = map[string]string{"testQparam": "value"}
+				want = TEST_SUCCESS_CONTENT_TYPE
+			}
+			response := testServiceEndpoint.Dryrun(context.Background(), request)
+			if response.Headers["Content-Type"] != want {
+				t.Errorf("invalid value for response header Content-Type, got %v, want %v",
+					response.Headers["Content-Type"], want)
+			}
+			if response.Headers[TEST_EXTRA_HEADER_KEY] != TEST_EXTRA_HEADER_VALUE {
+				t.Errorf("invalid value for response header extra-header")
+			}
+		}(i%2 == 0)
+	}
+	wg.Wait()
+}
+
+func TestServiceEndpointRequestID(t *testing.T) {
+	gotRequestID := ""
+	testServiceEndpoint := NewServiceEndpoint(
+		EventSpec{},
+		func(ctx context.Context, se ServiceEvent, lgr logger.Logger) string {
+			gotRequestID = lgr.RequestID
+			return TEST_AWS_RESPONSE_OK
+		},
+		logger.NewLogger(),
+		map[string]string{},
+		nil,
+	)
+	ctx := lambdacontext.NewContext(context.Background(), &lambdacontext.LambdaContext{
+		AwsRequestID: "request-1",
+	})
+	testServiceEndpoint.Dryrun(ctx, events.APIGatewayProxyRequest{})
+	if gotRequestID != "request-1" {
+		t.Errorf("invocation logger request id got %v, want request-1", gotRequestID)
+	}
+}
+
+// flushOnErrorTests for table testing of the flush on error log mode of the service endpoint
+var flushOnErrorTests = []struct {
+	testName  string
+	fail      bool
+	wantDebug bool
+}{
+	{"successful invocation", false, false},
+	{"failed invocation", true, true},
+}
+
+func TestServiceEndpointFlushOnError(t *testing.T) {
+	for _, tt := range flushOnErrorTests {
+		t.Run(tt.testName, func(t *testing.T) {
+			sink := logger.NewMemorySink(logger.TRACE)
+			lgr := logger.NewLogger()
+			lgr.MinLevel = logger.TRACE
+			lgr.Mode = logger.FLUSH_ON_ERROR_MODE
+			lgr.Sinks = []logger.Sink{sink}
+			fail := tt.fail
+			testServiceEndpoint := NewServiceEndpoint(
+				EventSpec{},
+				func(ctx context.Context, se ServiceEvent, lgr logger.Logger) string {
+					lgr.LogTxt(logger.DEBUG, "Test debug log")
+					if fail {
+						panic("test failure")
+					}
+					return TEST_AWS_RESPONSE_OK
+				},
+				lgr,
+				map[string]string{},
+				nil,
+			)
+			testServiceEndpoint.Dryrun(context.Background(), events.APIGatewayProxyRequest{})
+
+			hasDebug, hasInfo := false, false
+			for _, lg := range sink.Logs() {
+				hasDebug = hasDebug || lg.LogLevel == logger.DEBUG
+				hasInfo = hasInfo || lg.LogLevel == logger.INFO
+			}
+			if hasDebug != tt.wantDebug {
+				t.Errorf("debug logs written got %v, want %v", hasDebug, tt.wantDebug)
+			}
+			if !hasInfo {
+				t.Errorf("info logs not streamed in flush on error mode")
+			}
+		})
+	}
+}
+
+func TestServiceEndpointLoggerContext(t *testing.T) {
+	var invocationLgr logger.Logger
+	testServiceEndpoint := NewServiceEndpoint(
+		EventSpec{},
+		func(ctx context.Context, se ServiceEvent, lgr logger.Logger) string {
+			invocationLgr = lgr
+			logger.FromContext(ctx).With(map[string]interface{}{"userId": "1234"}).LogTxt(logger.INFO, "Test repository log")
+			return TEST_AWS_RESPONSE_OK
+		},
+		logger.NewLogger(),
+		map[string]string{},
+		nil,
+	)
+	var ctx context.Context
+	testServiceEndpoint.Dryrun(ctx, events.APIGatewayProxyRequest{})
+
+	found := false
+	for _, lg := range invocationLgr.LogHistory.Logs() {
+		found = found || (lg.Text == "Test repository log" && lg.Data["userId"] == "1234")
+	}
+	if !found {
+		t.Errorf("context logger does not log to the invocation log history")
+	}
+}
+
+// generatedIDPattern matches the ids generated by newRequestID
+var generatedIDPattern = regexp.MustCompile(`^[0-9a-f]{32}$`)
+
+// correlationIDTests for table testing of the correlation id of the service endpoint
+var correlationIDTests = []struct {
+	testName          string
+	headers           map[string]string
+	wantCorrelationID string
+}{
+	{"correlation id header", map[string]string{CORRELATION_ID_HEADER: "correlation-1"}, "correlation-1"},
+	{"lower case correlation id header", map[string]string{"x-correlation-id": "correlation-2"}, "correlation-2"},
+	{"correlation id header charset", map[string]string{CORRELATION_ID_HEADER: "1:a_b.c-D"}, "1:a_b.c-D"},
+	{"max length correlation id header", map[string]string{CORRELATION_ID_HEADER: strings.Repeat("a", 128)},
+		strings.Repeat("a", 128)},
+	{"generated correlation id", map[string]string{}, ""},
+	{"oversized correlation id header", map[string]string{CORRELATION_ID_HEADER: strings.Repeat("a", 129)}, ""},
+	{"invalid correlation id header", map[string]string{CORRELATION_ID_HEADER: "id\n{\"level\":\"ERROR\"}"}, ""},
+	{"correlation id header with spaces", map[string]string{CORRELATION_ID_HEADER: "correlation 1"}, ""},
+}
+
+func TestServiceEndpointCorrelationID(t *testing.T) {
+	for _, tt := range correlationIDTests {
+		t.Run(tt.testName, func(t *testing.T) {
+			var se ServiceEvent
+			var invocationLgr logger.Logger
+			testServiceEndpoint := NewServiceEndpoint(
+				EventSpec{},
+				func(ctx context.Context, event ServiceEvent, lgr logger.Logger) string {
+					se, invocationLgr = event, lgr
+					return TEST_AWS_RESPONSE_OK
+				},
+				logger.NewLogger(),
+				map[string]string{},
+				nil,
+			)
+			response := testServiceEndpoint.Dryrun(context.Background(), events.APIGatewayProxyRequest{
+				Headers:        tt.headers,
+				RequestContext: events.APIGatewayProxyRequestContext{RequestID: "api-request-1"},
+			})
+
+			got := response.Headers[CORRELATION_ID_HEADER]
+			if tt.wantCorrelationID != "" && got != tt.wantCorrelationID {
+				t.Errorf("correlation id header got %v, want %v", got, tt.wantCorrelationID)
+			}
+			if tt.wantCorrelationID == "" && !generatedIDPattern.MatchString(got) {
+				t.Errorf("correlation id header got %v, want a generated id", got)
+			}
+			if got == "" || se.CorrelationID != got {
+				t.Errorf("service event correlation id got %v, want %v", se.CorrelationID, got)
+			}
+			for _, lg := range invocationLgr.LogHistory.Logs() {
+				if lg.CorrelationID != got || lg.RequestID != "api-request-1" {
+					t.Errorf("log correlation id %v request id %v, want %v and api-request-1",
+						lg.CorrelationID, lg.RequestID, got)
+				}
+			}
+		})
+	}
+}
+
+func TestServiceEndpointSensitiveAttributes(t *testing.T) {
+	sink := logger.NewMemorySink(logger.TRACE)
+	lgr := logger.NewLogger()
+	lgr.Sinks = []logger.Sink{sink}
+	testServiceEndpoint := NewServiceEndpoint(
+		EventSpec{
+			RequiredRequestBody: ReqEventSpec{
+				ReqEventAttributes: map[string]interface{}{
+					"firstName": NewReqEvenAttrib("string", true, 2, 50),
+					"profile": map[string]interface{}{
+						"nationalId": NewReqEvenAttrib("string", true, 4, 50).Sensitive(),
+					},
+				},
+			},
+		},
+		func(ctx context.Context, se ServiceEvent, lgr logger.Logger) string {
+			return `{"nationalId": "` + se.RequestBody["profile"].(map[string]interface{})["nationalId"].(string) + `"}`
+		},
+		lgr,
+		map[string]string{},
+		nil,
+	)
+	response := testServiceEndpoint.Dryrun(context.Background(), events.APIGatewayProxyRequest{
+		Body: `{"firstName": "juan", "profile": {"nationalId": "1234-5678"}}`,
+	})
+	if response.Body != `{"nationalId": "1234-5678"}` {
+		t.Errorf("redaction modified the service event, got %v", response.Body)
+	}
+
+	logs := sink.Logs()
+	if len(logs) == 0 {
+		t.Fatalf("no logs written")
+	}
+	for _, lg := range logs {
+		if strings.Contains(lg.Text, "1234-5678") || strings.Contains(fmt.Sprint(lg.Data), "1234-5678") {
+			t.Errorf("sensitive attribute logged: %v %v", lg.Text, lg.Data)
+		}
+	}
+}
+
+func TestServiceEndpointPanicStack(t *testing.T) {
+	var invocationLgr logger.Logger
+	testServiceEndpoint := NewServiceEndpoint(
+		EventSpec{},
+		func(ctx context.Context, se ServiceEvent, lgr logger.Logger) string {
+			invocationLgr = lgr
+			var users map[string]string
+			users["1234"] = "juan"
+			return TEST_AWS_RESPONSE_OK
+		},
+		logger.NewLogger(),
+		map[string]string{},
+		nil,
+	)
+	response := testServiceEndpoint.Dryrun(context.Background(), events.APIGatewayProxyRequest{})
+	if response.StatusCode != int(INTERNAL_SERVER_ERROR) {
+		t.Errorf("panic status code got %v", response.StatusCode)
+	}
+
+	var fatal *logger.Log
+	for _, lg := range invocationLgr.LogHistory.Logs() {
+		if lg.LogLevel == logger.FATAL {
+			fatalLog := lg
+			fatal = &fatalLog
+		}
+	}
+	if fatal == nil {
+		t.Fatalf("runtime error panic not logged")
+	}
+	if !strings.Contains(fatal.Stack, "aws_service_endpoint_test.go") || fatal.Caller == "" || len(fatal.Errors) != 1 {
+		t.Errorf("panic log missing stack, caller or error chain: %v", fatal)
+	}
+}
+
+func TestServiceEndpointMetricsFlush(t *testing.T) {
+	defaultSink := metrics.DefaultSink
+	defer func() { metrics.DefaultSink = defaultSink }()
+	sink := metrics.NewMemorySink()
+	metrics.DefaultSink = sink
+
+	testServiceEndpoint := NewServiceEndpoint(
+		EventSpec{},
+		func(ctx context.Context, se ServiceEvent, lgr logger.Logger) string {
+			metrics.FromContext(ctx).PutMetric("UsersCreated", 1, metrics.COUNT)
+			return TEST_AWS_RESPONSE_OK
+		},
+		logger.NewLogger(),
+		map[string]string{},
+		nil,
+	)
+	ctx := metrics.RecorderIntoContext(context.Background(), metrics.NewPrometheusRecorder())
+	testServiceEndpoint.Dryrun(ctx, events.APIGatewayProxyRequest{})
+	testServiceEndpoint.Dryrun(ctx, events.APIGatewayProxyRequest{})
+
+	docs := sink.Documents()
+	if len(docs) != 2 || !reflect.DeepEqual(sink.Values("UsersCreated"), []float64{1, 1}) {
+		t.Errorf("invocation metrics not flushed per invocation, got %v", docs)
+	}
+	if docs[0]["correlationId"] == "" || docs[0]["correlationId"] == docs[1]["correlationId"] {
+		t.Errorf("invocation metrics not tagged with the correlation id, got %v", docs)
+	}
+}
+
+// requestRecorder keeps the recorded request metrics in memory
+type requestRecorder struct {
+	mu       sync.Mutex
+	requests []metrics.RequestMetrics
+}
+
+func (rr *requestRecorder) RecordRequest(rm metrics.RequestMetrics) error {
+	rr.mu.Lock()
+	defer rr.mu.Unlock()
+	rr.requests = append(rr.requests, rm)
+	return nil
+}
+
+var serviceEndpointRequestMetricsTests = []struct {
+	testName          string
+	userID            string
+	panicPayload      interface{}
+	statusCode        int
+	validationFailure string
+	functionRan       bool
+}{
+	{"success", "1234", nil, 200, "", true},
+	{"path param validation failure", "12", nil, 400, "Path Parameter", false},
+	{"http exception", "1234", HTTPException{StatusCode: 409, ErrorMessage: "conflict"}, 409, "", true},
+	{"internal server error", "1234", "runtime failure", 500, "", true},
+}
+
+func TestServiceEndpointRequestMetrics(t *testing.T) {
+	for _, tt := range serviceEndpointRequestMetricsTests {
+		tt := tt
+		t.Run(tt.testName, func(t *testing.T) {
+			testServiceEndpoint := NewServiceEndpoint(
+				EventSpec{
+					RequiredPathParams: ReqEventSpec{
+						ReqEventAttributes: map[string]interface{}{
+							"userId": NewReqEvenAttrib("string", true, 4, 50),
+						},
+					},
+				},
+				func(ctx context.Context, se ServiceEvent, lgr logger.Logger) string {
+					if tt.panicPayload != nil {
+						panic(tt.panicPayload)
+					}
+					return TEST_AWS_RESPONSE_OK
+				},
+				logger.NewLogger(),
+				map[string]string{},
+				nil,
+			)
+			recorder := &requestRecorder{}
+			ctx := metrics.RecorderIntoContext(context.Background(), recorder)
+			response := testServiceEndpoint.Dryrun(ctx, events.APIGatewayProxyRequest{
+				Resource:       "/user/{userId}",
+				PathParameters: map[string]string{"userId": tt.userID},
+			})
+
+			if len(recorder.requests) != 1 {
+				t.Fatalf("request metrics recorded %v times, want 1", len(recorder.requests))
+			}
+			rm := recorder.requests[0]
+			if rm.Endpoint != "/user/{userId}" || rm.StatusCode != tt.statusCode || rm.StatusCode != response.StatusCode {
+				t.Errorf("request metrics endpoint %v status %v, want /user/{userId} status %v",
+					rm.Endpoint, rm.StatusCode, tt.statusCode)
+			}
+			if rm.ValidationFailure != tt.validationFailure {
+				t.Errorf("validation failure got %q, want %q", rm.ValidationFailure, tt.validationFailure)
+			}
+			if rm.ValidationDuration <= 0 || (rm.FunctionDuration > 0) != tt.functionRan {
+				t.Errorf("phase durations got validation %v function %v", rm.ValidationDuration, rm.FunctionDuration)
+			}
+			if (rm.ResponseDuration > 0) != (tt.statusCode == 200) {
+				t.Errorf("response duration got %v", rm.ResponseDuration)
+			}
+			if rm.CorrelationID == "" {
+				t.Errorf("request metrics missing correlation id, got %+v", rm)
+			}
+		})
+	}
+}
+
+func TestIsColdStart(t *testing.T) {
+	atomic.StoreInt32(&coldStart, 1)
+	if !isColdStart() {
+		t.Errorf("first invocation is not a cold start")
+	}
+	if isColdStart() {
+		t.Errorf("second invocation is a cold start")
+	}
+}
+
+// newTestTracerProvider will create a tracer provider exporting the spans synchronously to an in-memory exporter
+func newTestTracerProvider() (*sdktrace.TracerProvider, *tracetest.InMemoryExporter) {
+	exporter := tracetest.NewInMemoryExporter()
+	return sdktrace.NewTracerProvider(sdktrace.WithSyncer(exporter)), exporter
+}
+
+// spanByName will return the first exported span with the name
+func spanByName(exporter *tracetest.InMemoryExporter, name string) (tracetest.SpanStub, bool) {
+	for _, span := range exporter.GetSpans() {
+		if span.Name == name {
+			return span, true
+		}
+	}
+	return tracetest.SpanStub{}, false
+}
+
+// spanAttributes will return the attributes of the span as a map
+func spanAttributes(span tracetest.SpanStub) map[attribute.Key]interface{} {
+	attrs := map[attribute.Key]interface{}{}
+	for _, kv := range span.Attributes {
+		attrs[kv.Key] = kv.Value.AsInterface()
+	}
+	return attrs
+}
+
+func TestServiceEndpointTracing(t *testing.T) {
+	tp, exporter := newTestTracerProvider()
+	memorySink := logger.NewMemorySink(logger.INFO)
+	lgr := logger.NewLogger()
+	lgr.Sinks = []logger.Sink{memorySink}
+	lgr.Mode = logger.STREAM_MODE
+	var fnSpanContext trace.SpanContext
+	testServiceEndpoint := NewServiceEndpoint(
+		EventSpec{
+			RequiredPathParams: ReqEventSpec{
+				ReqEventAttributes: map[string]interface{}{
+					"userId": NewReqEvenAttrib("string", true, 4, 50),
+				},
+			},
+		},
+		func(ctx context.Context, se ServiceEvent, lgr logger.Logger) string {
+			fnSpanContext = trace.SpanContextFromContext(ctx)
+			lgr.LogTxt(logger.INFO, "Getting user")
+			return TEST_AWS_RESPONSE_OK
+		},
+		lgr,
+		map[string]string{},
+		nil,
+	)
+	ctx := tracing.TracerProviderIntoContext(context.Background(), tp)
+	testServiceEndpoint.Dryrun(ctx, events.APIGatewayProxyRequest{
+		Resource:       "/user/{userId}",
+		HTTPMethod:     "GET",
+		Headers:        map[string]string{"Traceparent": "00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01"},
+		PathParameters: map[string]string{"userId": "1234"},
+	})
+
+	invocation, ok := spanByName(exporter, INVOCATION_SPAN)
+	if !ok {
+		t.Fatalf("invocation span not exported, got %v", exporter.GetSpans())
+	}
+	if invocation.SpanContext.TraceID().String() != "4bf92f3577b34da6a3ce929d0e0e4736" ||
+		invocation.Parent.SpanID().String() != "00f067aa0ba902b7" || !invocation.Parent.IsRemote() ||
+		invocation.SpanKind != trace.SpanKindServer {
+		t.Errorf("invocation span does not continue the traceparent, got %+v", invocation)
+	}
+	attrs := spanAttributes(invocation)
+	if attrs["http.route"] != "/user/{userId}" || attrs["http.status_code"] != int64(200) {
+		t.Errorf("invocation span attributes got %v", attrs)
+	}
+	for _, name := range []string{
+		PARSE_BODY_SPAN,
+		VALIDATION_SPAN_PREFIX + "Request Body",
+		VALIDATION_SPAN_PREFIX + "Query Parameter",
+		VALIDATION_SPAN_PREFIX + "Path Parameter",
+		SERVICE_FUNCTION_SPAN,
+	} {
+		span, ok := spanByName(exporter, name)
+		if !ok {
+			t.Errorf("span %v not exported", name)
+			continue
+		}
+		if !span.Parent.Equal(invocation.SpanContext) || span.Status.Code == codes.Error {
+			t.Errorf("span %v not a successful child of the invocation span, got %+v", name, span)
+		}
+	}
+	fnSpan, _ := spanByName(exporter, SERVICE_FUNCTION_SPAN)
+	if !fnSpanContext.Equal(fnSpan.SpanContext) {
+		t.Errorf("service function span not carried by the context, got %+v", fnSpanContext)
+	}
+
+	for _, lg := range memorySink.Logs() {
+		wantSpanID := invocation.SpanContext.SpanID().String()
+		if lg.Text == "Getting user" {
+			wantSpanID = fnSpan.SpanContext.SpanID().String()
+		}
+		if lg.TraceID != "4bf92f3577b34da6a3ce929d0e0e4736" || lg.SpanID != wantSpanID {
+			t.Errorf("log %q linked to trace %v span %v, want span %v", lg.Text, lg.TraceID, lg.SpanID, wantSpanID)
+		}
+	}
+}
+
+func TestServiceEndpointTracingValidationFailure(t *testing.T) {
+	tp, exporter := newTestTracerProvider()
+	testServiceEndpoint := NewServiceEndpoint(
+		EventSpec{
+			RequiredPathParams: ReqEventSpec{
+				ReqEventAttributes: map[string]interface{}{
+					"userId": NewReqEvenAttrib("string", true, 4, 50),
+				},
+			},
+		},
+		func(ctx context.Context, se ServiceEvent, lgr logger.Logger) string {
+			return TEST_AWS_RESPONSE_OK
+		},
+		logger.NewLogger(),
+		map[string]string{},
+		nil,
+	)
+	ctx := tracing.TracerProviderIntoContext(context.Background(), tp)
+	testServiceEndpoint.Dryrun(ctx, events.APIGatewayProxyRequest{
+		Headers:        map[string]string{"X-Amzn-Trace-Id": "Root=1-5759e988-bd862e3fe1be46a994272793;Parent=53995c3f42cd8ad8;Sampled=1"},
+		PathParameters: map[string]string{"userId": "12"},
+	})
+
+	invocation, _ := spanByName(exporter, INVOCATION_SPAN)
+	if invocation.SpanContext.TraceID().String() != "5759e988bd862e3fe1be46a994272793" {
+		t.Errorf("invocation span does not continue the x-ray trace, got %+v", invocation.SpanContext)
+	}
+	if len(invocation.Events) != 1 || invocation.Status.Code == codes.Error {
+		t.Errorf("bad request not recorded as a client error, got %+v", invocation)
+	}
+	validation, _ := spanByName(exporter, VALIDATION_SPAN_PREFIX+"Path Parameter")
+	if validation.Status.Code != codes.Error {
+		t.Errorf("failed validation span status got %v", validation.Status)
+	}
+	if _, ok := spanByName(exporter, SERVICE_FUNCTION_SPAN); ok {
+		t.Errorf("service function span exported for a bad request")
+	}
+}
+
+var tracingServiceFunctionExceptionTests = []struct {
+	testName       string
+	exception      interface{}
+	wantStatusCode int
+	wantError      bool
+}{
+	{"client error", HTTPException{StatusCode: int(RESOURCE_CONFLICT), ErrorMessage: "user exists"}, 409, false},
+	{"server error", HTTPException{StatusCode: int(INTERNAL_SERVER_ERROR), ErrorMessage: "db down"}, 500, true},
+	{"panic", "nil map", 500, true},
+}
+
+func TestServiceEndpointTracingServiceFunctionException(t *testing.T) {
+	for _, tt := range tracingServiceFunctionExceptionTests {
+		t.Run(tt.testName, func(t *testing.T) {
+			tp, exporter := newTestTracerProvider()
+			testServiceEndpoint := NewServiceEndpoint(
+				EventSpec{},
+				func(ctx context.Context, se ServiceEvent, lgr logger.Logger) string {
+					panic(tt.exception)
+				},
+				logger.NewLogger(),
+				map[string]string{},
+				nil,
+			)
+			response := testServiceEndpoint.Dryrun(tracing.TracerProviderIntoContext(context.Background(), tp),
+				events.APIGatewayProxyRequest{})
+			if response.StatusCode != tt.wantStatusCode {
+				t.Errorf("status code got %v, want %v", response.StatusCode, tt.wantStatusCode)
+			}
+			for _, name := range []string{SERVICE_FUNCTION_SPAN, INVOCATION_SPAN} {
+				span, ok := spanByName(exporter, name)
+				if !ok {
+					t.Fatalf("%v span not exported", name)
+				}
+				if (span.Status.Code == codes.Error) != tt.wantError {
+					t.Errorf("%v span status got %v, want error %v", name, span.Status, tt.wantError)
+				}
+			}
+		})
+	}
+}
+
+var tracingLambdaEnvTests = []struct {
+	testName     string
+	env          string
+	wantContinue bool
+}{
+	{"active tracing on", "Root=1-5759e988-bd862e3fe1be46a994272793;Parent=53995c3f42cd8ad8;Sampled=1", true},
+	{"active tracing off", "Root=1-5759e988-bd862e3fe1be46a994272793;Parent=53995c3f42cd8ad8;Sampled=0", false},
+}
+
+func TestServiceEndpointTracingLambdaEnv(t *testing.T) {
+	env := os.Getenv(tracing.XRAY_TRACE_ENV)
+	defer os.Setenv(tracing.XRAY_TRACE_ENV, env)
+	for _, tt := range tracingLambdaEnvTests {
+		t.Run(tt.testName, func(t *testing.T) {
+			os.Setenv(tracing.XRAY_TRACE_ENV, tt.env)
+			tp, exporter := newTestTracerProvider()
+			testServiceEndpoint := NewServiceEndpoint(
+				EventSpec{},
+				func(ctx context.Context, se ServiceEvent, lgr logger.Logger) string {
+					return TEST_AWS_RESPONSE_OK
+				},
+				logger.NewLogger(),
+				map[string]string{},
+				nil,
+			)
+			testServiceEndpoint.Dryrun(tracing.TracerProviderIntoContext(context.Background(), tp),
+				events.APIGatewayProxyRequest{})
+
+			invocation, ok := spanByName(exporter, INVOCATION_SPAN)
+			if !ok {
+				t.Fatalf("invocation span not exported, got %v", exporter.GetSpans())
+			}
+			continued := invocation.SpanContext.TraceID().String() == "5759e988bd862e3fe1be46a994272793"
+			if continued != tt.wantContinue || !invocation.SpanContext.IsSampled() {
+				t.Errorf("invocation span got trace %v sampled %v, want x-ray trace continued %v",
+					invocation.SpanContext.TraceID(), invocation.SpanContext.IsSampled(), tt.wantContinue)
+			}
+			if _, ok := spanByName(exporter, SERVICE_FUNCTION_SPAN); !ok {
+				t.Errorf("service function span not exported")
+			}
+		})
+	}
+}
+
+func TestServiceEndpointRedirectStdLog(t *testing.T) {
+	functionName := lambdacontext.FunctionName
+	defer func() { lambdacontext.FunctionName = functionName }()
+	lambdacontext.FunctionName = "create_user"
+
+	memorySink := logger.NewMemorySink(logger.INFO)
+	lgr := logger.NewLogger()
+	lgr.Sinks = []logger.Sink{memorySink}
+	testServiceEndpoint := NewServiceEndpoint(
+		EventSpec{},
+		func(ctx context.Context, se ServiceEvent, lgr logger.Logger) string {
+			log.Println("Library log")
+			return TEST_AWS_RESPONSE_OK
+		},
+		lgr,
+		map[string]string{},
+		nil,
+	)
+	output := log.Writer()
+	testServiceEndpoint.Dryrun(context.Background(), events.APIGatewayProxyRequest{
+		Headers: map[string]string{CORRELATION_ID_HEADER: "correlation-1"},
+	})
+
+	if log.Writer() != output {
+		t.Errorf("standard logger output not restored after the invocation")
+	}
+	for _, lg := range memorySink.Logs() {
+		if lg.Text == "Library log" {
+			if lg.CorrelationID != "correlation-1" || lg.LogLevel != logger.INFO {
+				t.Errorf("standard log recorded with correlation id %v level %v", lg.CorrelationID, lg.LogLevel)
+			}
+			return
+		}
+	}
+	t.Errorf("standard log not recorded by the request logger")
+}
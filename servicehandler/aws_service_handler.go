@@ -1,18 +1,24 @@
 package servicehandler
 
 import (
+	"context"
 	"encoding/json"
+	"fmt"
 	"go-micro/logger"
+	"go-micro/tracing"
 	"reflect"
-	"strconv"
+	"runtime/debug"
 
 	"github.com/aws/aws-lambda-go/events"
+	"go.opentelemetry.io/otel/codes"
 )
 
-// AWSServiceHandler is the aws implementation of ServiceHandler
+// AWSServiceHandler is the aws implementation of ServiceHandler.
+// Context carries the invocation span the parsing and validation spans are started from.
 type AWSServiceHandler struct {
-	Event  events.APIGatewayProxyRequest
-	Logger logger.Logger
+	Event   events.APIGatewayProxyRequest
+	Logger  logger.Logger
+	Context context.Context
 }
 
 // NewService will crete new AWSServiceHandler instance
@@ -23,17 +29,44 @@ func (ah AWSServiceHandler) NewServiceEvent(es EventSpec, options interface{}) S
 
 	identity := ah.Event.RequestContext.Identity
 
+	requestBody, queryParams, pathParams := parseServiceEventParams(
+		ah.Context,
+		ah.Logger,
+		es,
+		requestEndpoint,
+		ah.Event.Body,
+		ah.Event.QueryStringParameters,
+		ah.Event.PathParameters,
+	)
+
+	return ServiceEvent{
+		PathParams:    pathParams,
+		RequestBody:   requestBody,
+		QueryParams:   queryParams,
+		Identity:      identity,
+		CorrelationID: ah.Logger.CorrelationID,
+		Options:       options,
+	}
+}
+
+// parseServiceEventParams will convert the request body, query params and path params to maps
+// and check them against the event specification. It will raise a bad request http exception
+// on the first parameter that does not match the specification. The body parsing and the validation
+// of each parameter are traced as child spans of ctx.
+func parseServiceEventParams(ctx context.Context, lgr logger.Logger, es EventSpec, requestEndpoint string,
+	body string, queryParamsMapBuffer map[string]string, pathParamsMapBuffer map[string]string) (
+	map[string]interface{}, map[string]interface{}, map[string]interface{}) {
 	var requestBody map[string]interface{}
-	queryParamsMapBuffer := ah.Event.QueryStringParameters
-	pathParamsMapBuffer := ah.Event.PathParameters
 
 	// Convert JSON String body to map
-	json.Unmarshal([]byte(ah.Event.Body), &requestBody)
+	_, parseSpan := tracing.Start(ctx, PARSE_BODY_SPAN)
+	json.Unmarshal([]byte(body), &requestBody)
+	parseSpan.End()
 
-	ah.Logger.LogObj(logger.INFO, "Parsing Request Body", requestBody, "", false)
-	parseCode, errMsg := recursiveAttributeCheck(requestEndpoint, es.RequiredRequestBody, requestBody, 0)
+	lgr.LogObj(logger.INFO, "Parsing Request Body", requestBody, "", false)
+	parseCode, errMsg := traceValidation(ctx, REQ_BODY, requestEndpoint, es.RequiredRequestBody, requestBody)
 	if parseCode != ATTRIBUTE_OK {
-		ah.Logger.LogTxt(logger.ERROR, "Invalid Request Body, "+errMsg)
+		lgr.LogTxt(logger.ERROR, "Invalid Request Body, "+errMsg)
 		causePanic(REQ_BODY, parseCode, errMsg)
 	}
 
@@ -42,10 +75,10 @@ func (ah AWSServiceHandler) NewServiceEvent(es EventSpec, options interface{}) S
 	for k, v := range queryParamsMapBuffer {
 		queryParams[k] = v
 	}
-	ah.Logger.LogObj(logger.INFO, "Parsing Query Params", queryParams, "", false)
-	parseCode, errMsg = recursiveAttributeCheck(requestEndpoint, es.RequiredQueryParams, queryParams, 0)
+	lgr.LogObj(logger.INFO, "Parsing Query Params", queryParams, "", false)
+	parseCode, errMsg = traceValidation(ctx, QUERY_PARAMS, requestEndpoint, es.RequiredQueryParams, queryParams)
 	if parseCode != ATTRIBUTE_OK {
-		ah.Logger.LogTxt(logger.ERROR, "Invalid Query Params, "+errMsg)
+		lgr.LogTxt(logger.ERROR, "Invalid Query Params, "+errMsg)
 		causePanic(QUERY_PARAMS, parseCode, errMsg)
 	}
 
@@ -54,26 +87,35 @@ func (ah AWSServiceHandler) NewServiceEvent(es EventSpec, options interface{}) S
 	for k, v := range pathParamsMapBuffer {
 		pathParams[k] = v
 	}
-	ah.Logger.LogObj(logger.INFO, "Parsing Path Params", pathParams, "", false)
-	parseCode, errMsg = recursiveAttributeCheck(requestEndpoint, es.RequiredPathParams, pathParams, 0)
+	lgr.LogObj(logger.INFO, "Parsing Path Params", pathParams, "", false)
+	parseCode, errMsg = traceValidation(ctx, PATH_PARAMS, requestEndpoint, es.RequiredPathParams, pathParams)
 	if parseCode != ATTRIBUTE_OK {
-		ah.Logger.LogTxt(logger.ERROR, "Invalid Path params, "+errMsg)
+		lgr.LogTxt(logger.ERROR, "Invalid Path params, "+errMsg)
 		causePanic(PATH_PARAMS, parseCode, errMsg)
 	}
 
-	return ServiceEvent{
-		PathParams:  pathParams,
-		RequestBody: requestBody,
-		QueryParams: queryParams,
-		Identity:    identity,
-		Options:     options,
+	return requestBody, queryParams, pathParams
+}
+
+// traceValidation will check the params against the event specification in a child span of ctx,
+// setting the error status of the span when they don't match.
+func traceValidation(ctx context.Context, paramType int, requestEndpoint string, res ReqEventSpec,
+	params map[string]interface{}) (int, string) {
+	_, span := tracing.Start(ctx, VALIDATION_SPAN_PREFIX+parameterMap[paramType])
+	defer span.End()
+	parseCode, errMsg := recursiveAttributeCheck(requestEndpoint, res, params, 0)
+	if parseCode != ATTRIBUTE_OK {
+		span.SetStatus(codes.Error, errMsg)
 	}
+	return parseCode, errMsg
 }
 
 func (ah AWSServiceHandler) NewHTTPResponse(sr ServiceResponse) interface{} {
-	ah.Logger.LogTxt(
+	ah.Logger.LogFields(
 		logger.INFO,
-		"Creating new HTTP Response. Status Code <"+strconv.Itoa(sr.StatusCode)+">. Return Body: "+sr.ReturnBody,
+		"Creating new HTTP Response",
+		logger.Int("statusCode", sr.StatusCode),
+		logger.String("returnBody", sr.ReturnBody),
 	)
 	return events.APIGatewayProxyResponse{
 		StatusCode:      sr.StatusCode,
@@ -86,33 +128,14 @@ func (ah AWSServiceHandler) NewHTTPResponse(sr ServiceResponse) interface{} {
 func (ah AWSServiceHandler) HandleExceptions(recoverPayload interface{}, returnHeaders map[string]string) interface{} {
 	if recoverPayload != nil {
 		returnHeaders["Content-Type"] = "text/plain"
+		logRecoverPayload(ah.Logger, recoverPayload)
 		if reflect.TypeOf(recoverPayload).String() != "servicehandler.HTTPException" {
-			switch reflect.TypeOf(recoverPayload).String() {
-			case "string":
-				ah.Logger.LogTxt(
-					logger.FATAL,
-					"Internal Server Error. "+recoverPayload.(string),
-				)
-			case "runtime.errorString":
-				errorString := recoverPayload.(error).Error()
-				ah.Logger.LogTxt(
-					logger.FATAL,
-					"Internal Server Error. "+errorString,
-				)
-			case "map[string]string":
-				jsonstr, _ := json.Marshal(recoverPayload)
-				ah.Logger.LogTxt(logger.FATAL, string(jsonstr))
-			}
 			return ah.NewHTTPResponse(ServiceResponse{
 				StatusCode:    int(INTERNAL_SERVER_ERROR),
 				ReturnBody:    "Internal Server Error",
 				ReturnHeaders: returnHeaders,
 			}).(events.APIGatewayProxyResponse)
 		}
-		ah.Logger.LogTxt(
-			logger.ERROR,
-			recoverPayload.(HTTPException).ErrorMessage,
-		)
 		return ah.NewHTTPResponse(ServiceResponse{
 			StatusCode:    recoverPayload.(HTTPException).StatusCode,
 			ReturnBody:    recoverPayload.(HTTPException).ErrorMessage,
@@ -121,3 +144,28 @@ func (ah AWSServiceHandler) HandleExceptions(recoverPayload interface{}, returnH
 	}
 	return nil
 }
+
+// logRecoverPayload will log a recovered panic payload. HTTPExceptions are logged as errors,
+// anything else is logged as a fatal internal server error with the goroutine stack of the panic.
+// It must be called from the deferred function recovering the panic for the stack to include the panic.
+func logRecoverPayload(lgr logger.Logger, recoverPayload interface{}) {
+	if reflect.TypeOf(recoverPayload).String() != "servicehandler.HTTPException" {
+		stack := debug.Stack()
+		switch payload := recoverPayload.(type) {
+		case string:
+			lgr.LogPanic(logger.FATAL, "Internal Server Error. "+payload, recoverPayload, stack)
+		case error:
+			lgr.LogPanic(logger.FATAL, "Internal Server Error. "+payload.Error(), recoverPayload, stack)
+		case map[string]string:
+			jsonstr, _ := json.Marshal(recoverPayload)
+			lgr.LogPanic(logger.FATAL, string(jsonstr), recoverPayload, stack)
+		default:
+			lgr.LogPanic(logger.FATAL, fmt.Sprintf("Internal Server Error. %v", recoverPayload), recoverPayload, stack)
+		}
+		return
+	}
+	lgr.LogTxt(
+		logger.ERROR,
+		recoverPayload.(HTTPException).ErrorMessage,
+	)
+}
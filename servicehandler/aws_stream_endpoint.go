@@ -0,0 +1,282 @@
+package servicehandler
+
+import (
+	"context"
+	"go-micro/logger"
+	"strconv"
+
+	"github.com/aws/aws-lambda-go/events"
+)
+
+/* DynamoDB Streams or Kinesis record passed to the record function */
+type StreamRecord struct {
+	EventID        string
+	EventName      string
+	EventSource    string
+	SequenceNumber string
+	PartitionKey   string
+	Keys           map[string]interface{}
+	NewImage       map[string]interface{}
+	OldImage       map[string]interface{}
+	Data           map[string]interface{}
+	Options        interface{}
+}
+
+// RecordFunction is the function type of a stream record processor implementation
+type RecordFunction func(ctx context.Context, sr StreamRecord, logger logger.Logger) error
+
+/* Failed record reported back to lambda for checkpointing */
+type BatchItemFailure struct {
+	ItemIdentifier string `json:"itemIdentifier"`
+}
+
+/* Partial batch response of stream processors. Requires ReportBatchItemFailures on the event source mapping */
+type StreamBatchResponse struct {
+	BatchItemFailures []BatchItemFailure `json:"batchItemFailures"`
+}
+
+type AWSDynamoDBStreamEndpoint struct {
+	handler interface{}
+}
+
+type AWSKinesisStreamEndpoint struct {
+	handler interface{}
+}
+
+// dynamoDBAttributeToInterface will convert a DynamoDB attribute value to its plain go value.
+// Numbers are converted to float64 the same way JSON numbers are parsed.
+func dynamoDBAttributeToInterface(av events.DynamoDBAttributeValue) interface{} {
+	switch av.DataType() {
+	case events.DataTypeString:
+		return av.String()
+	case events.DataTypeNumber:
+		if number, err := strconv.ParseFloat(av.Number(), 64); err == nil {
+			return number
+		}
+		return av.Number()
+	case events.DataTypeBoolean:
+		return av.Boolean()
+	case events.DataTypeBinary:
+		return av.Binary()
+	case events.DataTypeMap:
+		return dynamoDBImageToMap(av.Map())
+	case events.DataTypeList:
+		list := make([]interface{}, 0, len(av.List()))
+		for _, v := range av.List() {
+			list = append(list, dynamoDBAttributeToInterface(v))
+		}
+		return list
+	case events.DataTypeStringSet:
+		return av.StringSet()
+	case events.DataTypeNumberSet:
+		numberSet := make([]interface{}, 0, len(av.NumberSet()))
+		for _, v := range av.NumberSet() {
+			numberSet = append(numberSet, dynamoDBAttributeToInterface(events.NewNumberAttribute(v)))
+		}
+		return numberSet
+	case events.DataTypeBinarySet:
+		return av.BinarySet()
+	}
+	return nil
+}
+
+// dynamoDBImageToMap will convert a DynamoDB stream image to map[string]interface{}
+func dynamoDBImageToMap(image map[string]events.DynamoDBAttributeValue) map[string]interface{} {
+	if image == nil {
+		return nil
+	}
+	ret := make(map[string]interface{}, len(image))
+	for k, v := range image {
+		ret[k] = dynamoDBAttributeToInterface(v)
+	}
+	return ret
+}
+
+// streamBatchItem is a stream record along with its raw kinesis data
+type streamBatchItem struct {
+	record StreamRecord
+	data   []byte
+}
+
+// parseStreamRecord will parse and validate the payload of a record. A record failing the parsing or the
+// validation can never succeed, so it is logged and reported as invalid instead of raising the exception.
+func parseStreamRecord(evh AWSEventHandler, es EventSpec, item streamBatchItem) (sr StreamRecord, valid bool) {
+	defer func() {
+		if err := recover(); err != nil {
+			ex, ok := err.(HTTPException)
+			if !ok {
+				panic(err)
+			}
+			evh.Logger.LogTxt(logger.ERROR,
+				"Skipping invalid Stream Record <"+item.record.SequenceNumber+">. "+ex.ErrorMessage)
+			valid = false
+		}
+	}()
+
+	sr = item.record
+	payload := sr.NewImage
+	if item.data != nil {
+		sr.Data = evh.ParsePayload(STREAM_RECORD, item.data)
+		payload = sr.Data
+	} else if payload == nil {
+		payload = sr.OldImage
+	}
+	evh.ValidatePayload(sr.EventSource, STREAM_RECORD, es.RequiredRequestBody, payload)
+	return sr, true
+}
+
+// processStreamRecord will parse, validate and execute the record function on a single record.
+// Invalid records are skipped. Errors and panics of the record function are returned as error.
+func processStreamRecord(ctx context.Context, evh AWSEventHandler, es EventSpec, rf RecordFunction,
+	item streamBatchItem) (recordError error) {
+	defer func() {
+		if err := recover(); err != nil {
+			recordError = evh.HandleExceptions(err)
+		}
+	}()
+
+	evh.Logger.LogTxt(logger.INFO, "Processing Stream Record <"+item.record.SequenceNumber+">..")
+	sr, valid := parseStreamRecord(evh, es, item)
+	if !valid {
+		return nil
+	}
+
+	// Execute the record function
+	if err := rf(ctx, sr, evh.Logger); err != nil {
+		evh.Logger.LogTxt(logger.ERROR, "Record Function failed. "+err.Error())
+		return err
+	}
+	return nil
+}
+
+// processStreamBatch will process the records in order and stop at the first failure of the record function.
+// The failed record is reported so lambda checkpoints the stream before it and retries from there. Invalid
+// records are skipped so they don't block the shard until they expire.
+func processStreamBatch(ctx context.Context, evh AWSEventHandler, es EventSpec, rf RecordFunction,
+	items []streamBatchItem) StreamBatchResponse {
+	response := StreamBatchResponse{
+		BatchItemFailures: []BatchItemFailure{},
+	}
+	evh.Logger.LogTxt(logger.INFO, "Processing "+strconv.Itoa(len(items))+" Stream Records..")
+	for _, item := range items {
+		failure := BatchItemFailure{ItemIdentifier: item.record.SequenceNumber}
+		if ctx.Err() != nil {
+			// Remaining records are retried on the next invocation
+			evh.Logger.LogTxt(logger.WARN, "Stopping stream processing. "+ctx.Err().Error())
+			response.BatchItemFailures = append(response.BatchItemFailures, failure)
+			break
+		}
+		if err := processStreamRecord(ctx, evh, es, rf, item); err != nil {
+			evh.Logger.LogTxt(logger.ERROR, "Stream Record <"+failure.ItemIdentifier+"> failed, checkpointing batch")
+			response.BatchItemFailures = append(response.BatchItemFailures, failure)
+			break
+		}
+	}
+	return response
+}
+
+// newStreamEndpointHandler will wrap the batch processing with the logger lifecycle of the endpoints
+func newStreamEndpointHandler(ctx context.Context, lgr logger.Logger, es EventSpec, rf RecordFunction,
+	items []streamBatchItem) (response StreamBatchResponse) {
+	// Create the request-scoped logger of the invocation
+	reqLgr := lgr.Derive()
+	reqLgr.RequestID = lambdaRequestID(ctx)
+	ctx = logger.IntoContext(ctx, reqLgr)
+	defer redirectStdLog(reqLgr)()
+
+	// Initialize Event Handler
+	reqLgr.LogTxt(logger.INFO, "Initializing AWS Stream Handler..")
+	evh := AWSEventHandler{
+		Logger: reqLgr,
+	}
+	defer func() {
+		reqLgr.Flush(len(response.BatchItemFailures) > 0)
+	}()
+	return processStreamBatch(ctx, evh, es, rf, items)
+}
+
+// NewDynamoDBStreamEndpoint will create the aws DynamoDB Streams endpoint instance.
+// The NewImage of each record (OldImage for removed items) is validated against the RequiredRequestBody
+// of the EventSpec.
+func NewDynamoDBStreamEndpoint(es EventSpec, rf RecordFunction, lgr logger.Logger,
+	options interface{}) *AWSDynamoDBStreamEndpoint {
+	lgr = redactSensitiveAttributes(lgr, es)
+	genericStreamEndpoint := func(ctx context.Context, event events.DynamoDBEvent) (StreamBatchResponse, error) {
+		items := make([]streamBatchItem, 0, len(event.Records))
+		for _, r := range event.Records {
+			items = append(items, streamBatchItem{
+				record: StreamRecord{
+					EventID:        r.EventID,
+					EventName:      r.EventName,
+					EventSource:    r.EventSource,
+					SequenceNumber: r.Change.SequenceNumber,
+					Keys:           dynamoDBImageToMap(r.Change.Keys),
+					NewImage:       dynamoDBImageToMap(r.Change.NewImage),
+					OldImage:       dynamoDBImageToMap(r.Change.OldImage),
+					Options:        options,
+				},
+			})
+		}
+		return newStreamEndpointHandler(ctx, lgr, es, rf, items), nil
+	}
+
+	return &AWSDynamoDBStreamEndpoint{
+		handler: genericStreamEndpoint,
+	}
+}
+
+// NewKinesisStreamEndpoint will create the aws Kinesis endpoint instance.
+// The data of each record is parsed as JSON and validated against the RequiredRequestBody of the EventSpec.
+func NewKinesisStreamEndpoint(es EventSpec, rf RecordFunction, lgr logger.Logger,
+	options interface{}) *AWSKinesisStreamEndpoint {
+	lgr = redactSensitiveAttributes(lgr, es)
+	genericStreamEndpoint := func(ctx context.Context, event events.KinesisEvent) (StreamBatchResponse, error) {
+		items := make([]streamBatchItem, 0, len(event.Records))
+		for _, r := range event.Records {
+			data := r.Kinesis.Data
+			if data == nil {
+				data = []byte{}
+			}
+			items = append(items, streamBatchItem{
+				record: StreamRecord{
+					EventID:        r.EventID,
+					EventName:      r.EventName,
+					EventSource:    r.EventSource,
+					SequenceNumber: r.Kinesis.SequenceNumber,
+					PartitionKey:   r.Kinesis.PartitionKey,
+					Options:        options,
+				},
+				data: data,
+			})
+		}
+		return newStreamEndpointHandler(ctx, lgr, es, rf, items), nil
+	}
+
+	return &AWSKinesisStreamEndpoint{
+		handler: genericStreamEndpoint,
+	}
+}
+
+// Execute will trigger the execution of aws lambda
+func (ae AWSDynamoDBStreamEndpoint) Execute() {
+	awsLambdaStart(ae.handler)
+}
+
+// Dryrun will run the stream handler without invoking the awslambda
+func (ae AWSDynamoDBStreamEndpoint) Dryrun(ctx context.Context, event events.DynamoDBEvent) StreamBatchResponse {
+	f := ae.handler.(func(context.Context, events.DynamoDBEvent) (StreamBatchResponse, error))
+	out, _ := f(ctx, event)
+	return out
+}
+
+// Execute will trigger the execution of aws lambda
+func (ae AWSKinesisStreamEndpoint) Execute() {
+	awsLambdaStart(ae.handler)
+}
+
+// Dryrun will run the stream handler without invoking the awslambda
+func (ae AWSKinesisStreamEndpoint) Dryrun(ctx context.Context, event events.KinesisEvent) StreamBatchResponse {
+	f := ae.handler.(func(context.Context, events.KinesisEvent) (StreamBatchResponse, error))
+	out, _ := f(ctx, event)
+	return out
+}
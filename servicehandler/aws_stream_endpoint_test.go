@@ -0,0 +1,301 @@
+package servicehandler
+
+import (
+	"context"
+	"errors"
+	"go-micro/logger"
+	"go-micro/logger/logtest"
+	"reflect"
+	"testing"
+
+	"github.com/aws/aws-lambda-go/events"
+)
+
+// streamEventSpec is the record specification used in stream endpoint testing
+var streamEventSpec = EventSpec{
+	RequiredRequestBody: ReqEventSpec{
+		ReqEventAttributes: map[string]interface{}{
+			"userId": NewReqEvenAttrib("string", true, 4, 50),
+		},
+	},
+}
+
+// newDynamoDBMockRecord will create a DynamoDB stream record with the given images
+func newDynamoDBMockRecord(sequenceNumber string, eventName string,
+	newImage map[string]events.DynamoDBAttributeValue,
+	oldImage map[string]events.DynamoDBAttributeValue) events.DynamoDBEventRecord {
+	return events.DynamoDBEventRecord{
+		EventID:     "event-" + sequenceNumber,
+		EventName:   eventName,
+		EventSource: "aws:dynamodb",
+		Change: events.DynamoDBStreamRecord{
+			SequenceNumber: sequenceNumber,
+			Keys: map[string]events.DynamoDBAttributeValue{
+				"userId": events.NewStringAttribute("user-" + sequenceNumber),
+			},
+			NewImage: newImage,
+			OldImage: oldImage,
+		},
+	}
+}
+
+// newKinesisMockRecord will create a Kinesis record with the given data
+func newKinesisMockRecord(sequenceNumber string, data string) events.KinesisEventRecord {
+	return events.KinesisEventRecord{
+		EventID:     "event-" + sequenceNumber,
+		EventName:   "aws:kinesis:record",
+		EventSource: "aws:kinesis",
+		Kinesis: events.KinesisRecord{
+			SequenceNumber: sequenceNumber,
+			PartitionKey:   "partition-" + sequenceNumber,
+			Data:           []byte(data),
+		},
+	}
+}
+
+// newStreamTestFunction will create a record function that records the processed sequence numbers
+func newStreamTestFunction(processed *[]string) RecordFunction {
+	return func(ctx context.Context, sr StreamRecord, logger logger.Logger) error {
+		*processed = append(*processed, sr.SequenceNumber)
+		if sr.NewImage["userId"] == "fail" || sr.Data["userId"] == "fail" {
+			return errors.New("record function failed")
+		}
+		if sr.NewImage["userId"] == "panic" {
+			panic("unexpected error")
+		}
+		return nil
+	}
+}
+
+var dynamoDBStreamTests = []struct {
+	testName      string
+	records       []events.DynamoDBEventRecord
+	wantProcessed []string
+	wantFailures  []string
+}{
+	{
+		"test dynamodb stream valid records",
+		[]events.DynamoDBEventRecord{
+			newDynamoDBMockRecord("1", "INSERT", map[string]events.DynamoDBAttributeValue{
+				"userId": events.NewStringAttribute("user-1"),
+				"age":    events.NewNumberAttribute("21"),
+			}, nil),
+			newDynamoDBMockRecord("2", "REMOVE", nil, map[string]events.DynamoDBAttributeValue{
+				"userId": events.NewStringAttribute("user-2"),
+			}),
+		},
+		[]string{"1", "2"},
+		[]string{},
+	},
+	{
+		"test dynamodb stream invalid record",
+		[]events.DynamoDBEventRecord{
+			newDynamoDBMockRecord("1", "INSERT", map[string]events.DynamoDBAttributeValue{
+				"userId": events.NewStringAttribute("user-1"),
+			}, nil),
+			newDynamoDBMockRecord("2", "INSERT", map[string]events.DynamoDBAttributeValue{
+				"userId": events.NewNumberAttribute("2"),
+			}, nil),
+			newDynamoDBMockRecord("3", "INSERT", map[string]events.DynamoDBAttributeValue{
+				"userId": events.NewStringAttribute("user-3"),
+			}, nil),
+		},
+		[]string{"1", "3"},
+		[]string{},
+	},
+	{
+		"test dynamodb stream record function error",
+		[]events.DynamoDBEventRecord{
+			newDynamoDBMockRecord("1", "MODIFY", map[string]events.DynamoDBAttributeValue{
+				"userId": events.NewStringAttribute("fail"),
+			}, nil),
+			newDynamoDBMockRecord("2", "MODIFY", map[string]events.DynamoDBAttributeValue{
+				"userId": events.NewStringAttribute("user-2"),
+			}, nil),
+		},
+		[]string{"1"},
+		[]string{"1"},
+	},
+	{
+		"test dynamodb stream record function panic",
+		[]events.DynamoDBEventRecord{
+			newDynamoDBMockRecord("1", "MODIFY", map[string]events.DynamoDBAttributeValue{
+				"userId": events.NewStringAttribute("panic"),
+			}, nil),
+		},
+		[]string{"1"},
+		[]string{"1"},
+	},
+}
+
+var kinesisStreamTests = []struct {
+	testName      string
+	records       []events.KinesisEventRecord
+	wantProcessed []string
+	wantFailures  []string
+}{
+	{
+		"test kinesis stream valid records",
+		[]events.KinesisEventRecord{
+			newKinesisMockRecord("1", `{"userId": "user-1", "age": 21}`),
+			newKinesisMockRecord("2", `{"userId": "user-2"}`),
+		},
+		[]string{"1", "2"},
+		[]string{},
+	},
+	{
+		"test kinesis stream malformed data",
+		[]events.KinesisEventRecord{
+			newKinesisMockRecord("1", `{"userId": "user-1"}`),
+			newKinesisMockRecord("2", `not json`),
+		},
+		[]string{"1"},
+		[]string{},
+	},
+	{
+		"test kinesis stream missing attribute",
+		[]events.KinesisEventRecord{
+			newKinesisMockRecord("1", ``),
+		},
+		[]string{},
+		[]string{},
+	},
+	{
+		"test kinesis stream invalid record followed by a valid record",
+		[]events.KinesisEventRecord{
+			newKinesisMockRecord("1", `{"userId": "12"}`),
+			newKinesisMockRecord("2", `{"userId": "user-2"}`),
+			newKinesisMockRecord("3", `{"userId": "fail"}`),
+			newKinesisMockRecord("4", `{"userId": "user-4"}`),
+		},
+		[]string{"2", "3"},
+		[]string{"3"},
+	},
+	{
+		"test kinesis stream record function error",
+		[]events.KinesisEventRecord{
+			newKinesisMockRecord("1", `{"userId": "fail"}`),
+		},
+		[]string{"1"},
+		[]string{"1"},
+	},
+}
+
+// failureIdentifiers will collect the item identifiers of the batch item failures
+func failureIdentifiers(response StreamBatchResponse) []string {
+	identifiers := []string{}
+	for _, f := range response.BatchItemFailures {
+		identifiers = append(identifiers, f.ItemIdentifier)
+	}
+	return identifiers
+}
+
+func TestDynamoDBStreamEndpoint(t *testing.T) {
+	for _, tt := range dynamoDBStreamTests {
+		t.Run(tt.testName, func(t *testing.T) {
+			processed := []string{}
+			testEndpoint := NewDynamoDBStreamEndpoint(streamEventSpec, newStreamTestFunction(&processed),
+				logger.NewLogger(), nil)
+			response := testEndpoint.Dryrun(context.Background(), events.DynamoDBEvent{Records: tt.records})
+			if !reflect.DeepEqual(processed, tt.wantProcessed) {
+				t.Errorf("processed records %v, want %v", processed, tt.wantProcessed)
+			}
+			if got := failureIdentifiers(response); !reflect.DeepEqual(got, tt.wantFailures) {
+				t.Errorf("batch item failures %v, want %v", got, tt.wantFailures)
+			}
+		})
+	}
+}
+
+func TestKinesisStreamEndpoint(t *testing.T) {
+	for _, tt := range kinesisStreamTests {
+		t.Run(tt.testName, func(t *testing.T) {
+			processed := []string{}
+			testEndpoint := NewKinesisStreamEndpoint(streamEventSpec, newStreamTestFunction(&processed),
+				logger.NewLogger(), nil)
+			response := testEndpoint.Dryrun(context.Background(), events.KinesisEvent{Records: tt.records})
+			if !reflect.DeepEqual(processed, tt.wantProcessed) {
+				t.Errorf("processed records %v, want %v", processed, tt.wantProcessed)
+			}
+			if got := failureIdentifiers(response); !reflect.DeepEqual(got, tt.wantFailures) {
+				t.Errorf("batch item failures %v, want %v", got, tt.wantFailures)
+			}
+		})
+	}
+}
+
+func TestStreamEndpointCancelledContext(t *testing.T) {
+	processed := []string{}
+	testEndpoint := NewKinesisStreamEndpoint(EventSpec{}, newStreamTestFunction(&processed),
+		logger.NewLogger(), nil)
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	response := testEndpoint.Dryrun(ctx, events.KinesisEvent{Records: []events.KinesisEventRecord{
+		newKinesisMockRecord("1", `{}`),
+		newKinesisMockRecord("2", `{}`),
+	}})
+	if len(processed) != 0 || !reflect.DeepEqual(failureIdentifiers(response), []string{"1"}) {
+		t.Errorf("cancelled stream processing not checkpointed at first record")
+	}
+}
+
+func TestDynamoDBImageToMap(t *testing.T) {
+	got := dynamoDBImageToMap(map[string]events.DynamoDBAttributeValue{
+		"name":    events.NewStringAttribute("juan"),
+		"age":     events.NewNumberAttribute("21"),
+		"active":  events.NewBooleanAttribute(true),
+		"deleted": events.NewNullAttribute(),
+		"tags":    events.NewStringSetAttribute([]string{"a", "b"}),
+		"scores":  events.NewNumberSetAttribute([]string{"1", "2.5"}),
+		"address": events.NewMapAttribute(map[string]events.DynamoDBAttributeValue{
+			"city": events.NewStringAttribute("manila"),
+		}),
+		"phones": events.NewListAttribute([]events.DynamoDBAttributeValue{
+			events.NewStringAttribute("0917"),
+		}),
+	})
+	want := map[string]interface{}{
+		"name":    "juan",
+		"age":     float64(21),
+		"active":  true,
+		"deleted": nil,
+		"tags":    []string{"a", "b"},
+		"scores":  []interface{}{float64(1), float64(2.5)},
+		"address": map[string]interface{}{"city": "manila"},
+		"phones":  []interface{}{"0917"},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("dynamodb image to map got %v, want %v", got, want)
+	}
+}
+
+func TestStreamEndpointExecute(t *testing.T) {
+	started := []bool{}
+	awsLambdaStart = func(handler interface{}) {
+		_, isDynamoDB := handler.(func(context.Context, events.DynamoDBEvent) (StreamBatchResponse, error))
+		_, isKinesis := handler.(func(context.Context, events.KinesisEvent) (StreamBatchResponse, error))
+		started = append(started, isDynamoDB || isKinesis)
+	}
+	NewDynamoDBStreamEndpoint(EventSpec{}, nil, logger.NewLogger(), nil).Execute()
+	NewKinesisStreamEndpoint(EventSpec{}, nil, logger.NewLogger(), nil).Execute()
+	if !reflect.DeepEqual(started, []bool{true, true}) {
+		t.Errorf("stream endpoint handlers not started")
+	}
+}
+
+func TestStreamEndpointSkipsInvalidRecords(t *testing.T) {
+	processed := []string{}
+	rec := logtest.New()
+	testEndpoint := NewKinesisStreamEndpoint(streamEventSpec, newStreamTestFunction(&processed), rec.Logger, nil)
+	response := testEndpoint.Dryrun(context.Background(), events.KinesisEvent{Records: []events.KinesisEventRecord{
+		newKinesisMockRecord("1", `not json`),
+		newKinesisMockRecord("2", `{"userId": "user-2"}`),
+	}})
+	if !reflect.DeepEqual(processed, []string{"2"}) || len(response.BatchItemFailures) != 0 {
+		t.Errorf("invalid record not skipped, processed %v with failures %v", processed, response.BatchItemFailures)
+	}
+	rec.AssertOrder(t,
+		logtest.All(logtest.Level(logger.ERROR), logtest.Text("Skipping invalid Stream Record <1>")),
+		logtest.Text("Processing Stream Record <2>"),
+	)
+}
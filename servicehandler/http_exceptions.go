@@ -5,18 +5,22 @@ type StatusCode int
 const (
 	BAD_REQUEST           StatusCode = 400
 	RESOURCE_CONFLICT     StatusCode = 409
+	PAYLOAD_TOO_LARGE     StatusCode = 413
 	INTERNAL_SERVER_ERROR StatusCode = 500
 )
 
+// HTTPException is the panic payload converted to an error response by the service handlers.
+// ParameterLocation is the request parameter that failed the validation of a bad request, e.g. Query Parameter.
 type HTTPException struct {
-	StatusCode   int
-	ErrorMessage string
+	StatusCode        int
+	ErrorMessage      string
+	ParameterLocation string
 }
 
 /* Check if status code is valie */
 func (sc StatusCode) isValid() bool {
 	switch sc {
-	case BAD_REQUEST, RESOURCE_CONFLICT, INTERNAL_SERVER_ERROR:
+	case BAD_REQUEST, RESOURCE_CONFLICT, PAYLOAD_TOO_LARGE, INTERNAL_SERVER_ERROR:
 		return true
 	}
 	return false
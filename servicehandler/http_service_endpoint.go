@@ -0,0 +1,110 @@
+package servicehandler
+
+import (
+	"go-micro/logger"
+	"net/http"
+	"os"
+
+	"go.opentelemetry.io/otel/attribute"
+)
+
+// DEFAULT_HTTP_PORT is the port served by HTTPServiceEndpoint.Execute when PORT is not set
+const DEFAULT_HTTP_PORT = "8080"
+
+// HTTPServiceEndpoint serves a service function through net/http so it can be deployed off lambda,
+// e.g. in containers, Cloud Run or Knative. It implements http.Handler.
+// MaxBodyBytes is the request body size limit, requests exceeding it are rejected with 413.
+type HTTPServiceEndpoint struct {
+	MaxBodyBytes int64
+	handler      http.HandlerFunc
+}
+
+// httpListenAndServe is the trigger for http server execution that can be mocked in testing
+var httpListenAndServe = func(addr string, handler http.Handler) error {
+	return http.ListenAndServe(addr, handler)
+}
+
+// NewHTTPServiceEndpoint will create the net/http service endpoint instance. Path is the route template
+// of the endpoint like /user/{userId} that the path params are extracted from. An empty path serves
+// any request path without path params. The request body is limited to DEFAULT_MAX_BODY_BYTES.
+func NewHTTPServiceEndpoint(path string, es EventSpec, sf ServiceFunction, lgr logger.Logger,
+	retHeaders map[string]string, options interface{}) *HTTPServiceEndpoint {
+	lgr = redactSensitiveAttributes(lgr, es)
+	he := &HTTPServiceEndpoint{
+		MaxBodyBytes: DEFAULT_MAX_BODY_BYTES,
+	}
+	he.handler = func(w http.ResponseWriter, r *http.Request) {
+		// Create the request-scoped logger of the invocation
+		reqLgr := lgr.Derive()
+		reqLgr.RequestID = r.Header.Get("X-Request-Id")
+		reqLgr.CorrelationID = requestCorrelationID(r.Header.Get)
+		overrideLogLevel(&reqLgr, r.Header.Get)
+
+		pathParams := map[string]string{}
+		if path != "" {
+			var ok bool
+			if pathParams, ok = matchRoute(path, r.URL.Path); !ok {
+				http.NotFound(w, r)
+				return
+			}
+		}
+
+		// Trace the invocation as part of the trace of the caller
+		endpoint := endpointName(path, ANY_ENDPOINT)
+		ctx, span := startInvocationSpan(r.Context(), &reqLgr, r.Header.Get)
+		defer endInvocationSpan(ctx, span)
+		span.SetAttributes(
+			attribute.String("http.method", r.Method),
+			attribute.String("http.route", endpoint),
+			attribute.String("correlation.id", reqLgr.CorrelationID),
+		)
+		r = r.WithContext(ctx)
+
+		// Assemble the Return Headers of the invocation
+		reqRetHeaders := map[string]string{
+			"Content-Type": "application/json",
+		}
+		for k, v := range retHeaders {
+			reqRetHeaders[k] = v
+		}
+		reqRetHeaders[CORRELATION_ID_HEADER] = reqLgr.CorrelationID
+
+		// Initialize Service Handler
+		reqLgr.LogTxt(logger.INFO, "Initializing HTTP Service Handler..")
+		svh := HTTPServiceHandler{
+			Request:      r,
+			Writer:       w,
+			PathParams:   pathParams,
+			Logger:       reqLgr,
+			MaxBodyBytes: he.MaxBodyBytes,
+		}
+
+		response := executeServiceFunction(
+			ctx,
+			svh,
+			reqLgr,
+			endpoint,
+			es,
+			sf,
+			reqRetHeaders,
+			options,
+		).(ServiceResponse)
+		span.SetAttributes(attribute.Int("http.status_code", response.StatusCode))
+	}
+
+	return he
+}
+
+// ServeHTTP will run the service function on the http request
+func (he HTTPServiceEndpoint) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	he.handler(w, r)
+}
+
+// Execute will serve the endpoint on the port given by the PORT environment variable
+func (he HTTPServiceEndpoint) Execute() error {
+	port := os.Getenv("PORT")
+	if port == "" {
+		port = DEFAULT_HTTP_PORT
+	}
+	return httpListenAndServe(":"+port, he)
+}
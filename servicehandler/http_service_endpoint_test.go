@@ -0,0 +1,187 @@
+package servicehandler
+
+import (
+	"context"
+	"go-micro/logger"
+	"go-micro/tracing"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"strings"
+	"testing"
+)
+
+// httpServiceEndpointTests for table testing of the net/http service endpoint
+var httpServiceEndpointTests = []struct {
+	testName        string
+	path            string
+	wantStatusCode  int
+	wantBody        string
+	wantContentType string
+}{
+	{
+		"test http service endpoint valid request",
+		"/user/1234",
+		200,
+		TEST_AWS_RESPONSE_OK,
+		TEST_SUCCESS_CONTENT_TYPE,
+	},
+	{
+		"test http service endpoint bad request",
+		"/user/12",
+		400,
+		"Error in Path Parameter, INVALID ATTRIBUTE LENGTH. invalid length of attribute userId. min length: 4, max length: 50",
+		TEST_ERROR_CONTENT_TYPE,
+	},
+	{
+		"test http service endpoint route not found",
+		"/orders/1234",
+		404,
+		"404 page not found\n",
+		"text/plain; charset=utf-8",
+	},
+}
+
+func TestHTTPServiceEndpoint(t *testing.T) {
+	for _, tt := range httpServiceEndpointTests {
+		t.Run(tt.testName, func(t *testing.T) {
+			testServiceEndpoint := NewHTTPServiceEndpoint(
+				"/user/{userId}",
+				EventSpec{
+					RequiredPathParams: ReqEventSpec{
+						ReqEventAttributes: map[string]interface{}{
+							"userId": NewReqEvenAttrib("string", true, 4, 50),
+						},
+					},
+				},
+				func(ctx context.Context, se ServiceEvent, logger logger.Logger) string {
+					return TEST_AWS_RESPONSE_OK
+				},
+				logger.NewLogger(),
+				map[string]string{TEST_EXTRA_HEADER_KEY: TEST_EXTRA_HEADER_VALUE},
+				nil,
+			)
+			recorder := httptest.NewRecorder()
+			testServiceEndpoint.ServeHTTP(recorder, httptest.NewRequest(http.MethodGet, tt.path, strings.NewReader("")))
+			if recorder.Code != tt.wantStatusCode {
+				t.Errorf("http service endpoint status code got %v, want %v", recorder.Code, tt.wantStatusCode)
+			}
+			if recorder.Body.String() != tt.wantBody {
+				t.Errorf("http service endpoint response got %v, want %v", recorder.Body.String(), tt.wantBody)
+			}
+			if recorder.Header().Get("Content-Type") != tt.wantContentType {
+				t.Errorf("invalid value for response header Content-Type")
+			}
+		})
+	}
+}
+
+func TestHTTPServiceEndpointExecute(t *testing.T) {
+	gotAddr := ""
+	httpListenAndServe = func(addr string, handler http.Handler) error {
+		gotAddr = addr
+		return nil
+	}
+	os.Setenv("PORT", "9090")
+	defer os.Unsetenv("PORT")
+	NewHTTPServiceEndpoint("", EventSpec{}, nil, logger.NewLogger(), nil, nil).Execute()
+	if gotAddr != ":9090" {
+		t.Errorf("http service endpoint served on %v, want :9090", gotAddr)
+	}
+}
+
+func TestHTTPServiceEndpointCorrelationID(t *testing.T) {
+	testServiceEndpoint := NewHTTPServiceEndpoint(
+		"",
+		EventSpec{},
+		func(ctx context.Context, se ServiceEvent, logger logger.Logger) string {
+			return se.CorrelationID
+		},
+		logger.NewLogger(),
+		map[string]string{},
+		nil,
+	)
+
+	req := httptest.NewRequest(http.MethodGet, "/user", nil)
+	req.Header.Set(CORRELATION_ID_HEADER, "correlation-1")
+	recorder := httptest.NewRecorder()
+	testServiceEndpoint.ServeHTTP(recorder, req)
+	if recorder.Body.String() != "correlation-1" || recorder.Header().Get(CORRELATION_ID_HEADER) != "correlation-1" {
+		t.Errorf("correlation id not propagated, got %v and header %v",
+			recorder.Body.String(), recorder.Header().Get(CORRELATION_ID_HEADER))
+	}
+
+	recorder = httptest.NewRecorder()
+	testServiceEndpoint.ServeHTTP(recorder, httptest.NewRequest(http.MethodGet, "/user", nil))
+	if len(recorder.Body.String()) != 32 || recorder.Header().Get(CORRELATION_ID_HEADER) != recorder.Body.String() {
+		t.Errorf("correlation id not generated, got %v", recorder.Body.String())
+	}
+}
+
+func TestHTTPServiceEndpointTracing(t *testing.T) {
+	tp, exporter := newTestTracerProvider()
+	testServiceEndpoint := NewHTTPServiceEndpoint(
+		"/user/{userId}",
+		EventSpec{
+			RequiredPathParams: ReqEventSpec{
+				ReqEventAttributes: map[string]interface{}{
+					"userId": NewReqEvenAttrib("string", true, 4, 50),
+				},
+			},
+		},
+		func(ctx context.Context, se ServiceEvent, logger logger.Logger) string {
+			return TEST_AWS_RESPONSE_OK
+		},
+		logger.NewLogger(),
+		map[string]string{},
+		nil,
+	)
+
+	req := httptest.NewRequest(http.MethodGet, "/user/1234", nil)
+	req.Header.Set("traceparent", "00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01")
+	req = req.WithContext(tracing.TracerProviderIntoContext(req.Context(), tp))
+	testServiceEndpoint.ServeHTTP(httptest.NewRecorder(), req)
+
+	spans := exporter.GetSpans()
+	if len(spans) != 6 {
+		t.Fatalf("exported spans got %v, want 6", len(spans))
+	}
+	invocation, _ := spanByName(exporter, INVOCATION_SPAN)
+	if invocation.Parent.SpanID().String() != "00f067aa0ba902b7" || !invocation.Parent.IsRemote() {
+		t.Errorf("invocation span parent got %v, want the traceparent span", invocation.Parent.SpanID())
+	}
+	if attrs := spanAttributes(invocation); attrs["http.route"] != "/user/{userId}" ||
+		attrs["http.status_code"] != int64(200) {
+		t.Errorf("invocation span attributes got %v", attrs)
+	}
+	for _, span := range spans {
+		if span.SpanContext.TraceID().String() != "4bf92f3577b34da6a3ce929d0e0e4736" {
+			t.Errorf("span %v trace id got %v, want the incoming trace", span.Name, span.SpanContext.TraceID())
+		}
+		if span.Name != INVOCATION_SPAN && !span.Parent.Equal(invocation.SpanContext) {
+			t.Errorf("span %v not a child of the invocation span, got parent %v", span.Name, span.Parent.SpanID())
+		}
+	}
+}
+
+func TestHTTPServiceEndpointMaxBodyBytes(t *testing.T) {
+	testServiceEndpoint := NewHTTPServiceEndpoint(
+		"/user",
+		EventSpec{},
+		func(ctx context.Context, se ServiceEvent, logger logger.Logger) string {
+			return TEST_AWS_RESPONSE_OK
+		},
+		logger.NewLogger(),
+		map[string]string{},
+		nil,
+	)
+	if testServiceEndpoint.MaxBodyBytes != DEFAULT_MAX_BODY_BYTES {
+		t.Errorf("default max body bytes got %v, want %v", testServiceEndpoint.MaxBodyBytes, DEFAULT_MAX_BODY_BYTES)
+	}
+	testServiceEndpoint.MaxBodyBytes = 8
+	recorder := httptest.NewRecorder()
+	testServiceEndpoint.ServeHTTP(recorder, httptest.NewRequest(http.MethodPost, "/user", strings.NewReader(`{"userId": "1234"}`)))
+	if recorder.Code != 413 || recorder.Body.String() != "Request Body exceeds the limit of 8 bytes" {
+		t.Errorf("oversized request got %v %v, want 413", recorder.Code, recorder.Body.String())
+	}
+}
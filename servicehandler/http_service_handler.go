@@ -0,0 +1,135 @@
+package servicehandler
+
+import (
+	"go-micro/logger"
+	"io/ioutil"
+	"net"
+	"net/http"
+	"reflect"
+	"strconv"
+
+	"github.com/aws/aws-lambda-go/events"
+)
+
+// DEFAULT_MAX_BODY_BYTES is the request body size limit of the net/http service endpoints, the same as
+// the payload limit of api gateway
+const DEFAULT_MAX_BODY_BYTES int64 = 10 << 20
+
+// HTTPServiceHandler is the net/http implementation of ServiceHandler.
+// PathParams are the path parameters extracted from the route of the request.
+// MaxBodyBytes is the request body size limit, DEFAULT_MAX_BODY_BYTES when not set.
+type HTTPServiceHandler struct {
+	Request      *http.Request
+	Writer       http.ResponseWriter
+	PathParams   map[string]string
+	Logger       logger.Logger
+	MaxBodyBytes int64
+}
+
+// readBody will read the request body up to MaxBodyBytes. It will raise a payload too large http exception
+// if the body exceeds the limit and a bad request http exception if the body can't be read.
+func (hh HTTPServiceHandler) readBody() []byte {
+	if hh.Request.Body == nil {
+		return []byte{}
+	}
+	limit := hh.MaxBodyBytes
+	if limit <= 0 {
+		limit = DEFAULT_MAX_BODY_BYTES
+	}
+	body, err := ioutil.ReadAll(http.MaxBytesReader(hh.Writer, hh.Request.Body, limit))
+	if err == nil {
+		return body
+	}
+	// MaxBytesReader fails only after reading the limit, any other failure is a broken request
+	if int64(len(body)) >= limit {
+		errMsg := "Request Body exceeds the limit of " + strconv.FormatInt(limit, 10) + " bytes"
+		hh.Logger.LogTxt(logger.ERROR, errMsg)
+		panic(HTTPException{
+			StatusCode:        int(PAYLOAD_TOO_LARGE),
+			ErrorMessage:      errMsg,
+			ParameterLocation: parameterMap[REQ_BODY],
+		})
+	}
+	hh.Logger.LogTxt(logger.ERROR, "Failed reading Request Body, "+err.Error())
+	causePanic(REQ_BODY, INVALID_ATTRIBUTE_TYPE_ERROR, err.Error())
+	return nil
+}
+
+// NewServiceEvent will create the service event from the http request.
+// The request body is limited to MaxBodyBytes, the request is rejected instead of validating a truncated body.
+func (hh HTTPServiceHandler) NewServiceEvent(es EventSpec, options interface{}) ServiceEvent {
+	hh.Logger.LogTxt(logger.INFO, "Creating new service")
+
+	requestEndpoint := hh.Request.URL.Path
+
+	sourceIP, _, err := net.SplitHostPort(hh.Request.RemoteAddr)
+	if err != nil {
+		sourceIP = hh.Request.RemoteAddr
+	}
+	identity := events.APIGatewayRequestIdentity{
+		SourceIP:  sourceIP,
+		UserAgent: hh.Request.UserAgent(),
+	}
+
+	body := hh.readBody()
+
+	queryParamsMapBuffer := map[string]string{}
+	for k, v := range hh.Request.URL.Query() {
+		queryParamsMapBuffer[k] = v[0]
+	}
+
+	requestBody, queryParams, pathParams := parseServiceEventParams(
+		hh.Request.Context(),
+		hh.Logger,
+		es,
+		requestEndpoint,
+		string(body),
+		queryParamsMapBuffer,
+		hh.PathParams,
+	)
+
+	return ServiceEvent{
+		PathParams:    pathParams,
+		RequestBody:   requestBody,
+		QueryParams:   queryParams,
+		Identity:      identity,
+		CorrelationID: hh.Logger.CorrelationID,
+		Options:       options,
+	}
+}
+
+// NewHTTPResponse will write the service response to the http response writer
+func (hh HTTPServiceHandler) NewHTTPResponse(sr ServiceResponse) interface{} {
+	hh.Logger.LogFields(
+		logger.INFO,
+		"Creating new HTTP Response",
+		logger.Int("statusCode", sr.StatusCode),
+		logger.String("returnBody", sr.ReturnBody),
+	)
+	for k, v := range sr.ReturnHeaders {
+		hh.Writer.Header().Set(k, v)
+	}
+	hh.Writer.WriteHeader(sr.StatusCode)
+	hh.Writer.Write([]byte(sr.ReturnBody))
+	return sr
+}
+
+func (hh HTTPServiceHandler) HandleExceptions(recoverPayload interface{}, returnHeaders map[string]string) interface{} {
+	if recoverPayload != nil {
+		returnHeaders["Content-Type"] = "text/plain"
+		logRecoverPayload(hh.Logger, recoverPayload)
+		if reflect.TypeOf(recoverPayload).String() != "servicehandler.HTTPException" {
+			return hh.NewHTTPResponse(ServiceResponse{
+				StatusCode:    int(INTERNAL_SERVER_ERROR),
+				ReturnBody:    "Internal Server Error",
+				ReturnHeaders: returnHeaders,
+			})
+		}
+		return hh.NewHTTPResponse(ServiceResponse{
+			StatusCode:    recoverPayload.(HTTPException).StatusCode,
+			ReturnBody:    recoverPayload.(HTTPException).ErrorMessage,
+			ReturnHeaders: returnHeaders,
+		})
+	}
+	return nil
+}
@@ -0,0 +1,204 @@
+package servicehandler
+
+import (
+	"errors"
+	"go-micro/logger"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+// newHTTPMockRequest will create an http request with the given query string and body
+func newHTTPMockRequest(query string, body string) *http.Request {
+	req := httptest.NewRequest(http.MethodPost, "/department/IT?"+query, strings.NewReader(body))
+	req.Header.Set("User-Agent", "go-micro-test")
+	return req
+}
+
+var httpNewServiceTests = []struct {
+	testName   string
+	request    *http.Request
+	pathParams map[string]string
+	isValid    bool
+}{
+	{
+		"valid request",
+		newHTTPMockRequest("fields=firstname", `{"firstname": "juan", "middlename": "ponce", "lastname": "dela cruz"}`),
+		map[string]string{"department": "IT"},
+		true,
+	},
+	{
+		"invalid request body",
+		newHTTPMockRequest("fields=firstname", `{"firstname": ""}`),
+		map[string]string{"department": "IT"},
+		false,
+	},
+	{
+		"invalid query params",
+		newHTTPMockRequest("", `{"firstname": "juan", "middlename": "ponce", "lastname": "dela cruz"}`),
+		map[string]string{"department": "IT"},
+		false,
+	},
+	{
+		"invalid path params",
+		newHTTPMockRequest("fields=firstname", `{"firstname": "juan", "middlename": "ponce", "lastname": "dela cruz"}`),
+		map[string]string{},
+		false,
+	},
+}
+
+func TestHTTPNewServiceEvent(t *testing.T) {
+	eventSpec := EventSpec{
+		RequiredRequestBody: ReqEventSpec{
+			ReqEventAttributes: map[string]interface{}{
+				"firstname":  NewReqEvenAttrib("string", true, 2, 50),
+				"lastname":   NewReqEvenAttrib("string", true, 2, 50),
+				"middlename": NewReqEvenAttrib("string", true, 2, 50),
+			}},
+		RequiredQueryParams: ReqEventSpec{
+			ReqEventAttributes: map[string]interface{}{
+				"fields": NewReqEvenAttrib("string", true, 4, 50),
+			},
+		},
+		RequiredPathParams: ReqEventSpec{
+			ReqEventAttributes: map[string]interface{}{
+				"department": NewReqEvenAttrib("string", true, 2, 50),
+			},
+		},
+	}
+
+	for _, tt := range httpNewServiceTests {
+		t.Run(tt.testName, func(t *testing.T) {
+			defer func() {
+				if err := recover(); err == nil && !tt.isValid {
+					t.Error("Invalid request not caught")
+				}
+			}()
+			var serviceHandler ServiceHandler = HTTPServiceHandler{
+				Request:    tt.request,
+				Writer:     httptest.NewRecorder(),
+				PathParams: tt.pathParams,
+				Logger:     logger.NewLogger(),
+			}
+			serviceEvent := serviceHandler.NewServiceEvent(eventSpec, nil)
+			if serviceEvent.Identity.UserAgent != "go-micro-test" || serviceEvent.Identity.SourceIP == "" {
+				t.Errorf("Invalid service event identity %v", serviceEvent.Identity)
+			}
+			if serviceEvent.PathParams["department"] != "IT" || serviceEvent.QueryParams["fields"] != "firstname" {
+				t.Errorf("Invalid service event params")
+			}
+		})
+	}
+}
+
+// errorReader fails reading after returning its data
+type errorReader struct {
+	data string
+}
+
+func (er *errorReader) Read(p []byte) (int, error) {
+	if er.data == "" {
+		return 0, errors.New("connection reset")
+	}
+	n := copy(p, er.data)
+	er.data = er.data[n:]
+	return n, nil
+}
+
+func TestHTTPNewServiceEventBody(t *testing.T) {
+	var httpBodyTests = []struct {
+		testName       string
+		body           io.Reader
+		maxBodyBytes   int64
+		wantStatusCode int
+	}{
+		{"body within the limit", strings.NewReader(`{"firstname": "juan"}`), 21, 0},
+		{"body within the default limit", strings.NewReader(strings.Repeat(" ", 1<<20) + `{"firstname": "juan"}`), 0, 0},
+		{"body exceeding the limit", strings.NewReader(`{"firstname": "juan"}`), 20, 413},
+		{"body exceeding the default limit", strings.NewReader(strings.Repeat(" ", int(DEFAULT_MAX_BODY_BYTES)+1)), 0, 413},
+		{"body read failure", &errorReader{`{"firstname": "ju`}, 0, 400},
+	}
+	eventSpec := EventSpec{
+		RequiredRequestBody: ReqEventSpec{
+			ReqEventAttributes: map[string]interface{}{
+				"firstname": NewReqEvenAttrib("string", true, 2, 50),
+			}},
+	}
+	for _, tt := range httpBodyTests {
+		t.Run(tt.testName, func(t *testing.T) {
+			statusCode := 0
+			defer func() {
+				if ex, ok := recover().(HTTPException); ok {
+					statusCode = ex.StatusCode
+				}
+				if statusCode != tt.wantStatusCode {
+					t.Errorf("request body status code got %v, want %v", statusCode, tt.wantStatusCode)
+				}
+			}()
+			serviceHandler := HTTPServiceHandler{
+				Request:      httptest.NewRequest(http.MethodPost, "/user", tt.body),
+				Writer:       httptest.NewRecorder(),
+				Logger:       logger.NewLogger(),
+				MaxBodyBytes: tt.maxBodyBytes,
+			}
+			serviceEvent := serviceHandler.NewServiceEvent(eventSpec, nil)
+			if serviceEvent.RequestBody["firstname"] != "juan" {
+				t.Errorf("request body got %v", serviceEvent.RequestBody)
+			}
+		})
+	}
+}
+
+func TestHTTPNewResponse(t *testing.T) {
+	recorder := httptest.NewRecorder()
+	serviceHandler := HTTPServiceHandler{
+		Request: newHTTPMockRequest("", ""),
+		Writer:  recorder,
+		Logger:  logger.NewLogger(),
+	}
+	serviceHandler.NewHTTPResponse(ServiceResponse{
+		StatusCode:    201,
+		ReturnBody:    `{"message": "OK"}`,
+		ReturnHeaders: map[string]string{"Content-Type": "application/json"},
+	})
+	if recorder.Code != 201 || recorder.Body.String() != `{"message": "OK"}` {
+		t.Errorf("Invalid HTTP Service Response")
+	}
+	if recorder.Header().Get("Content-Type") != "application/json" {
+		t.Errorf("Invalid HTTP Service Response Headers")
+	}
+}
+
+func TestHTTPHandleExceptions(t *testing.T) {
+	var httpHandleExceptionTests = []struct {
+		testName       string
+		input          interface{}
+		wantStatusCode int
+	}{
+		{"internal server error test (string)", "error test", 500},
+		{"internal server error test (struct)", map[string]string{"testError": "testError"}, 500},
+		{"http exception test", HTTPException{StatusCode: 409, ErrorMessage: "conflict"}, 409},
+	}
+	for _, tt := range httpHandleExceptionTests {
+		t.Run(tt.testName, func(t *testing.T) {
+			recorder := httptest.NewRecorder()
+			sh := HTTPServiceHandler{
+				Request: newHTTPMockRequest("", ""),
+				Writer:  recorder,
+				Logger:  logger.NewLogger(),
+			}
+			defer func() {
+				sh.HandleExceptions(recover(), map[string]string{})
+				if recorder.Code != tt.wantStatusCode {
+					t.Errorf("Invalid http exception. status code got %v, want %v", recorder.Code, tt.wantStatusCode)
+				}
+				if recorder.Header().Get("Content-Type") != "text/plain" {
+					t.Errorf("Invalid http exception content type")
+				}
+			}()
+			panic(tt.input)
+		})
+	}
+}
@@ -0,0 +1,208 @@
+package servicehandler
+
+import (
+	"encoding/base64"
+	"go-micro/metrics"
+	"io/ioutil"
+	"net"
+	"net/http"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"github.com/aws/aws-lambda-go/events"
+	"github.com/aws/aws-lambda-go/lambdacontext"
+)
+
+// LOCAL_STAGE is the api gateway stage of requests served by the LocalServer
+const LOCAL_STAGE = "local"
+
+// LOCAL_METRICS_PATH is the default path the LocalServer serves the request metrics on in the Prometheus
+// text format
+const LOCAL_METRICS_PATH = "/metrics"
+
+// LocalRoute routes a local http request to its service endpoint. An empty Method matches any http method.
+// Path is an api gateway resource template like /user/{userId}, the last segment can be a greedy path
+// variable like /files/{proxy+}.
+type LocalRoute struct {
+	Method   string
+	Path     string
+	Endpoint *AWSServiceEndpoint
+}
+
+// LocalServer serves service endpoints through net/http for local development and integration tests.
+// Http requests are translated to APIGatewayProxyRequest and the endpoint responses back to http responses.
+// Identity and Authorizer are stubbed into the request context of every request. The request metrics of
+// the endpoints are recorded by Metrics and served on GET MetricsPath, an empty MetricsPath disables
+// serving them. Routes take precedence over MetricsPath, so a route matching it hides the metrics.
+type LocalServer struct {
+	Routes      []LocalRoute
+	Stage       string
+	Identity    events.APIGatewayRequestIdentity
+	Authorizer  map[string]interface{}
+	Metrics     *metrics.PrometheusRecorder
+	MetricsPath string
+}
+
+// NewLocalServer will create the LocalServer instance for the given routes
+func NewLocalServer(routes ...LocalRoute) *LocalServer {
+	return &LocalServer{
+		Routes:      routes,
+		Stage:       LOCAL_STAGE,
+		Metrics:     metrics.NewPrometheusRecorder(),
+		MetricsPath: LOCAL_METRICS_PATH,
+	}
+}
+
+// ServeLocal will serve the routes on the given address, e.g. ":8080"
+func ServeLocal(addr string, routes ...LocalRoute) error {
+	return http.ListenAndServe(addr, NewLocalServer(routes...))
+}
+
+// matchRoute will match the request path against a route template and extract the path parameters
+func matchRoute(template string, path string) (map[string]string, bool) {
+	templateSegments := strings.Split(strings.Trim(template, "/"), "/")
+	pathSegments := strings.Split(strings.Trim(path, "/"), "/")
+	pathParams := map[string]string{}
+	for i, ts := range templateSegments {
+		isParam := strings.HasPrefix(ts, "{") && strings.HasSuffix(ts, "}")
+		if isParam && strings.HasSuffix(ts, "+}") && i == len(templateSegments)-1 {
+			if i >= len(pathSegments) || pathSegments[i] == "" {
+				return nil, false
+			}
+			pathParams[strings.TrimSuffix(ts[1:], "+}")] = strings.Join(pathSegments[i:], "/")
+			return pathParams, true
+		}
+		if i >= len(pathSegments) {
+			return nil, false
+		}
+		if isParam {
+			if pathSegments[i] == "" {
+				return nil, false
+			}
+			pathParams[ts[1:len(ts)-1]] = pathSegments[i]
+		} else if ts != pathSegments[i] {
+			return nil, false
+		}
+	}
+	return pathParams, len(templateSegments) == len(pathSegments)
+}
+
+// newAPIGatewayProxyRequest will translate an http request to the api gateway proxy request of a route
+func (ls *LocalServer) newAPIGatewayProxyRequest(r *http.Request, resource string,
+	pathParams map[string]string, requestID string) (events.APIGatewayProxyRequest, error) {
+	body, err := ioutil.ReadAll(r.Body)
+	if err != nil {
+		return events.APIGatewayProxyRequest{}, err
+	}
+	isBase64Encoded := !utf8.Valid(body)
+	requestBody := string(body)
+	if isBase64Encoded {
+		requestBody = base64.StdEncoding.EncodeToString(body)
+	}
+
+	headers := map[string]string{}
+	for k, v := range r.Header {
+		headers[k] = v[0]
+	}
+	if r.Host != "" {
+		headers["Host"] = r.Host
+	}
+	queryParams := map[string]string{}
+	multiValueQueryParams := map[string][]string{}
+	for k, v := range r.URL.Query() {
+		queryParams[k] = v[0]
+		multiValueQueryParams[k] = v
+	}
+
+	identity := ls.Identity
+	if identity.SourceIP == "" {
+		identity.SourceIP, _, _ = net.SplitHostPort(r.RemoteAddr)
+	}
+	if identity.UserAgent == "" {
+		identity.UserAgent = r.UserAgent()
+	}
+
+	return events.APIGatewayProxyRequest{
+		Resource:                        resource,
+		Path:                            r.URL.Path,
+		HTTPMethod:                      r.Method,
+		Headers:                         headers,
+		MultiValueHeaders:               r.Header,
+		QueryStringParameters:           queryParams,
+		MultiValueQueryStringParameters: multiValueQueryParams,
+		PathParameters:                  pathParams,
+		RequestContext: events.APIGatewayProxyRequestContext{
+			RequestID:        requestID,
+			ResourcePath:     resource,
+			HTTPMethod:       r.Method,
+			Stage:            ls.Stage,
+			Protocol:         r.Proto,
+			Identity:         identity,
+			Authorizer:       ls.Authorizer,
+			RequestTimeEpoch: time.Now().UnixNano() / int64(time.Millisecond),
+		},
+		Body:            requestBody,
+		IsBase64Encoded: isBase64Encoded,
+	}, nil
+}
+
+// writeAPIGatewayProxyResponse will translate the api gateway proxy response of an endpoint to the http response
+func writeAPIGatewayProxyResponse(w http.ResponseWriter, response events.APIGatewayProxyResponse) {
+	for k, v := range response.Headers {
+		w.Header().Set(k, v)
+	}
+	for k, values := range response.MultiValueHeaders {
+		for _, v := range values {
+			w.Header().Add(k, v)
+		}
+	}
+	body := []byte(response.Body)
+	if response.IsBase64Encoded {
+		if decoded, err := base64.StdEncoding.DecodeString(response.Body); err == nil {
+			body = decoded
+		}
+	}
+	w.WriteHeader(response.StatusCode)
+	w.Write(body)
+}
+
+// ServeHTTP will dispatch the http request to the first matching route, serving the metrics on
+// MetricsPath when no route matches the path
+func (ls *LocalServer) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	methodNotAllowed := false
+	for _, route := range ls.Routes {
+		pathParams, ok := matchRoute(route.Path, r.URL.Path)
+		if !ok {
+			continue
+		}
+		if route.Method != "" && !strings.EqualFold(route.Method, r.Method) {
+			methodNotAllowed = true
+			continue
+		}
+
+		requestID := newRequestID()
+		event, err := ls.newAPIGatewayProxyRequest(r, route.Path, pathParams, requestID)
+		if err != nil {
+			http.Error(w, "Bad Request", http.StatusBadRequest)
+			return
+		}
+		ctx := lambdacontext.NewContext(r.Context(), &lambdacontext.LambdaContext{
+			AwsRequestID: requestID,
+		})
+		if ls.Metrics != nil {
+			ctx = metrics.RecorderIntoContext(ctx, ls.Metrics)
+		}
+		writeAPIGatewayProxyResponse(w, route.Endpoint.Dryrun(ctx, event))
+		return
+	}
+	if methodNotAllowed {
+		http.Error(w, "Method Not Allowed", http.StatusMethodNotAllowed)
+		return
+	}
+	if ls.Metrics != nil && ls.MetricsPath != "" && r.URL.Path == ls.MetricsPath && r.Method == http.MethodGet {
+		ls.Metrics.ServeHTTP(w, r)
+		return
+	}
+	http.NotFound(w, r)
+}
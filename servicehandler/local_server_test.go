@@ -0,0 +1,260 @@
+package servicehandler
+
+import (
+	"context"
+	"encoding/json"
+	"go-micro/logger"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/aws/aws-lambda-go/lambdacontext"
+)
+
+var matchRouteTests = []struct {
+	testName       string
+	template       string
+	path           string
+	wantPathParams map[string]string
+	wantMatch      bool
+}{
+	{"static route", "/user", "/user", map[string]string{}, true},
+	{"static route trailing slash", "/user", "/user/", map[string]string{}, true},
+	{"path param", "/user/{userId}", "/user/1234", map[string]string{"userId": "1234"}, true},
+	{
+		"multiple path params",
+		"/department/{department}/user/{userId}",
+		"/department/IT/user/1234",
+		map[string]string{"department": "IT", "userId": "1234"},
+		true,
+	},
+	{"greedy path param", "/files/{proxy+}", "/files/a/b/c.txt", map[string]string{"proxy": "a/b/c.txt"}, true},
+	{"greedy path param missing", "/files/{proxy+}", "/files", nil, false},
+	{"missing path param", "/user/{userId}", "/user", nil, false},
+	{"extra segment", "/user/{userId}", "/user/1234/orders", nil, false},
+	{"static mismatch", "/user", "/users", nil, false},
+}
+
+func TestMatchRoute(t *testing.T) {
+	for _, tt := range matchRouteTests {
+		t.Run(tt.testName, func(t *testing.T) {
+			pathParams, ok := matchRoute(tt.template, tt.path)
+			if ok != tt.wantMatch {
+				t.Errorf("route match got %v, want %v", ok, tt.wantMatch)
+			}
+			if ok && !reflect.DeepEqual(pathParams, tt.wantPathParams) {
+				t.Errorf("path params got %v, want %v", pathParams, tt.wantPathParams)
+			}
+		})
+	}
+}
+
+// newLocalTestServer will create a local server echoing the service event of the request
+func newLocalTestServer() *httptest.Server {
+	echoEndpoint := NewServiceEndpoint(
+		EventSpec{
+			RequiredPathParams: ReqEventSpec{
+				ReqEventAttributes: map[string]interface{}{
+					"userId": NewReqEvenAttrib("string", true, 4, 50),
+				},
+			},
+		},
+		func(ctx context.Context, se ServiceEvent, lgr logger.Logger) string {
+			lc, _ := lambdacontext.FromContext(ctx)
+			out, _ := json.Marshal(map[string]interface{}{
+				"pathParams":  se.PathParams,
+				"queryParams": se.QueryParams,
+				"requestBody": se.RequestBody,
+				"sourceIp":    se.Identity.SourceIP,
+				"requestId":   lc.AwsRequestID,
+			})
+			return string(out)
+		},
+		logger.NewLogger(),
+		map[string]string{"X-Local": "true"},
+		nil,
+	)
+	ls := NewLocalServer(
+		LocalRoute{Method: http.MethodPost, Path: "/user/{userId}", Endpoint: echoEndpoint},
+	)
+	return httptest.NewServer(ls)
+}
+
+func TestLocalServer(t *testing.T) {
+	server := newLocalTestServer()
+	defer server.Close()
+
+	resp, err := http.Post(server.URL+"/user/1234?fields=firstname", "application/json",
+		strings.NewReader(`{"firstname": "juan"}`))
+	if err != nil {
+		t.Fatalf("local server request failed: %v", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		t.Errorf("local server status code got %v, want %v", resp.StatusCode, http.StatusOK)
+	}
+	if resp.Header.Get("X-Local") != "true" || resp.Header.Get("Content-Type") != "application/json" {
+		t.Errorf("local server response headers not translated: %v", resp.Header)
+	}
+	body, _ := ioutil.ReadAll(resp.Body)
+	got := map[string]interface{}{}
+	json.Unmarshal(body, &got)
+	want := map[string]interface{}{
+		"pathParams":  map[string]interface{}{"userId": "1234"},
+		"queryParams": map[string]interface{}{"fields": "firstname"},
+		"requestBody": map[string]interface{}{"firstname": "juan"},
+		"sourceIp":    "127.0.0.1",
+		"requestId":   got["requestId"],
+	}
+	if !reflect.DeepEqual(got, want) || got["requestId"] == "" {
+		t.Errorf("local server service event got %v, want %v", got, want)
+	}
+}
+
+var localServerStatusTests = []struct {
+	testName       string
+	method         string
+	path           string
+	wantStatusCode int
+}{
+	{"bad request", http.MethodPost, "/user/12", http.StatusBadRequest},
+	{"not found", http.MethodPost, "/orders/1234", http.StatusNotFound},
+	{"method not allowed", http.MethodGet, "/user/1234", http.StatusMethodNotAllowed},
+}
+
+func TestLocalServerStatus(t *testing.T) {
+	server := newLocalTestServer()
+	defer server.Close()
+
+	for _, tt := range localServerStatusTests {
+		t.Run(tt.testName, func(t *testing.T) {
+			req, _ := http.NewRequest(tt.method, server.URL+tt.path, nil)
+			resp, err := http.DefaultClient.Do(req)
+			if err != nil {
+				t.Fatalf("local server request failed: %v", err)
+			}
+			resp.Body.Close()
+			if resp.StatusCode != tt.wantStatusCode {
+				t.Errorf("local server status code got %v, want %v", resp.StatusCode, tt.wantStatusCode)
+			}
+		})
+	}
+}
+
+func TestLocalServerMetrics(t *testing.T) {
+	server := newLocalTestServer()
+	defer server.Close()
+
+	for _, path := range []string{"/user/1234", "/user/12"} {
+		resp, err := http.Post(server.URL+path, "application/json", nil)
+		if err != nil {
+			t.Fatalf("local server request failed: %v", err)
+		}
+		resp.Body.Close()
+	}
+
+	resp, err := http.Get(server.URL + LOCAL_METRICS_PATH)
+	if err != nil {
+		t.Fatalf("local server metrics request failed: %v", err)
+	}
+	defer resp.Body.Close()
+	body, _ := ioutil.ReadAll(resp.Body)
+	if resp.StatusCode != http.StatusOK || !strings.HasPrefix(resp.Header.Get("Content-Type"), "text/plain") {
+		t.Fatalf("local server metrics status %v content type %v", resp.StatusCode, resp.Header.Get("Content-Type"))
+	}
+	for _, want := range []string{
+		`gomicro_invocations_total{endpoint="/user/{userId}"} 2`,
+		`gomicro_responses_total{endpoint="/user/{userId}",status_class="2xx"} 1`,
+		`gomicro_responses_total{endpoint="/user/{userId}",status_class="4xx"} 1`,
+		`gomicro_validation_failures_total{endpoint="/user/{userId}",location="Path Parameter"} 1`,
+		`gomicro_phase_duration_seconds_count{endpoint="/user/{userId}",phase="validation"} 2`,
+		`gomicro_phase_duration_seconds_count{endpoint="/user/{userId}",phase="function"} 1`,
+	} {
+		if !strings.Contains(string(body), want) {
+			t.Errorf("local server metrics missing %v, got\n%v", want, string(body))
+		}
+	}
+}
+
+// localMetricsPathTests for table testing of the metrics path of the local server
+var localMetricsPathTests = []struct {
+	testName        string
+	metricsPath     string
+	routePath       string
+	requestPath     string
+	wantStatusCode  int
+	wantRouteServed bool
+}{
+	{"default metrics path", LOCAL_METRICS_PATH, "/user", "/metrics", 200, false},
+	{"custom metrics path", "/_local/metrics", "/user", "/_local/metrics", 200, false},
+	{"custom metrics path moves the default", "/_local/metrics", "/user", "/metrics", 404, false},
+	{"disabled metrics path", "", "/user", "/metrics", 404, false},
+	{"route at the metrics path", LOCAL_METRICS_PATH, "/metrics", "/metrics", 200, true},
+	{"greedy route over the metrics path", LOCAL_METRICS_PATH, "/{proxy+}", "/metrics", 200, true},
+}
+
+func TestLocalServerMetricsPath(t *testing.T) {
+	for _, tt := range localMetricsPathTests {
+		t.Run(tt.testName, func(t *testing.T) {
+			routeEndpoint := NewServiceEndpoint(
+				EventSpec{},
+				func(ctx context.Context, se ServiceEvent, lgr logger.Logger) string {
+					return TEST_AWS_RESPONSE_OK
+				},
+				logger.NewLogger(),
+				map[string]string{},
+				nil,
+			)
+			ls := NewLocalServer(LocalRoute{Method: http.MethodGet, Path: tt.routePath, Endpoint: routeEndpoint})
+			ls.MetricsPath = tt.metricsPath
+			recorder := httptest.NewRecorder()
+			ls.ServeHTTP(recorder, httptest.NewRequest(http.MethodGet, tt.requestPath, nil))
+			if recorder.Code != tt.wantStatusCode {
+				t.Errorf("local server status code got %v, want %v", recorder.Code, tt.wantStatusCode)
+			}
+			if routeServed := recorder.Body.String() == TEST_AWS_RESPONSE_OK; routeServed != tt.wantRouteServed {
+				t.Errorf("local server served the route %v, want %v", routeServed, tt.wantRouteServed)
+			}
+		})
+	}
+}
+
+func TestLocalServerBinaryBody(t *testing.T) {
+	ls := NewLocalServer()
+	req := httptest.NewRequest(http.MethodPost, "/upload", strings.NewReader("\xff\xfe"))
+	event, err := ls.newAPIGatewayProxyRequest(req, "/upload", map[string]string{}, "request-1")
+	if err != nil || !event.IsBase64Encoded || event.Body != "//4=" {
+		t.Errorf("binary request body not base64 encoded, got %v", event.Body)
+	}
+	if event.RequestContext.Stage != LOCAL_STAGE || event.RequestContext.RequestID != "request-1" {
+		t.Errorf("invalid local request context %v", event.RequestContext)
+	}
+}
+
+func TestLocalServerConcurrentRequests(t *testing.T) {
+	server := newLocalTestServer()
+	defer server.Close()
+
+	var wg sync.WaitGroup
+	for i := 0; i < 20; i++ {
+		wg.Add(1)
+		go func(path string, want int) {
+			defer wg.Done()
+			resp, err := http.Post(server.URL+path, "application/json", strings.NewReader(`{}`))
+			if err != nil {
+				t.Errorf("local server request failed: %v", err)
+				return
+			}
+			resp.Body.Close()
+			if resp.StatusCode != want {
+				t.Errorf("local server status code got %v, want %v", resp.StatusCode, want)
+			}
+		}([]string{"/user/1234", "/user/12"}[i%2], []int{http.StatusOK, http.StatusBadRequest}[i%2])
+	}
+	wg.Wait()
+}
@@ -0,0 +1,315 @@
+package servicehandler
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/subtle"
+	"encoding/hex"
+	"fmt"
+	"go-micro/logger"
+	"go-micro/metrics"
+	"go-micro/tracing"
+	"os"
+	"strings"
+	"sync/atomic"
+	"time"
+
+	"github.com/aws/aws-lambda-go/lambdacontext"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// LOG_LEVEL_HEADER is the request header overriding the minimum log level of the invocation.
+// The override is only honored for trusted callers sending the DEBUG_TOKEN_HEADER matching
+// the LOG_DEBUG_TOKEN environment variable.
+const (
+	LOG_LEVEL_HEADER   = "X-Log-Level"
+	DEBUG_TOKEN_HEADER = "X-Debug-Token"
+	DEBUG_TOKEN_ENV    = "LOG_DEBUG_TOKEN"
+)
+
+// CORRELATION_ID_HEADER is the request header of the correlation ID tying the logs of a request
+// together across services. It is echoed in the response headers.
+const CORRELATION_ID_HEADER = "X-Correlation-Id"
+
+// Names of the spans traced by the service endpoints. The validation spans are named by the prefix and
+// the validated parameter, e.g. "validate Path Parameter".
+const (
+	INVOCATION_SPAN        = "invocation"
+	PARSE_BODY_SPAN        = "parse Request Body"
+	VALIDATION_SPAN_PREFIX = "validate "
+	SERVICE_FUNCTION_SPAN  = "service function"
+)
+
+// ServiceFunction is the function type of microservice funtion implementation
+type ServiceFunction func(ctx context.Context, se ServiceEvent, logger logger.Logger) string
+
+// lambdaRequestID will return the aws request id of the lambda invocation, empty outside of lambda
+func lambdaRequestID(ctx context.Context) string {
+	if ctx == nil {
+		return ""
+	}
+	if lc, ok := lambdacontext.FromContext(ctx); ok {
+		return lc.AwsRequestID
+	}
+	return ""
+}
+
+// newRequestID will generate a random request id
+func newRequestID() string {
+	b := make([]byte, 16)
+	rand.Read(b)
+	return hex.EncodeToString(b)
+}
+
+// MAX_CORRELATION_ID_LENGTH is the length limit of the correlation ID accepted from the request header
+const MAX_CORRELATION_ID_LENGTH = 128
+
+// isValidCorrelationID will check the correlation id is bounded and made of [A-Za-z0-9._:-] only,
+// so a caller can't inject arbitrary text into the logs and the response headers.
+func isValidCorrelationID(id string) bool {
+	if id == "" || len(id) > MAX_CORRELATION_ID_LENGTH {
+		return false
+	}
+	for i := 0; i < len(id); i++ {
+		c := id[i]
+		if !(c >= 'a' && c <= 'z' || c >= 'A' && c <= 'Z' || c >= '0' && c <= '9' ||
+			c == '.' || c == '_' || c == ':' || c == '-') {
+			return false
+		}
+	}
+	return true
+}
+
+// requestCorrelationID will return the correlation id of the request header, generating one when
+// missing or invalid
+func requestCorrelationID(header func(key string) string) string {
+	if id := header(CORRELATION_ID_HEADER); isValidCorrelationID(id) {
+		return id
+	}
+	return newRequestID()
+}
+
+// CorrelationIDFromHeaders will return the correlation id of lambda request headers, matched
+// case-insensitively like the service endpoints do, generating one when missing or invalid.
+// It is meant for handlers that don't run through a service endpoint.
+func CorrelationIDFromHeaders(headers map[string]string) string {
+	return requestCorrelationID(func(key string) string {
+		return headerValue(headers, key)
+	})
+}
+
+// headerValue will return the value of a request header matched case-insensitively
+func headerValue(headers map[string]string, key string) string {
+	if v, ok := headers[key]; ok {
+		return v
+	}
+	for k, v := range headers {
+		if strings.EqualFold(k, key) {
+			return v
+		}
+	}
+	return ""
+}
+
+// overrideLogLevel will set the minimum log level of the request-scoped logger from the
+// LOG_LEVEL_HEADER when the caller is trusted. Untrusted or invalid overrides are ignored.
+func overrideLogLevel(lgr *logger.Logger, header func(key string) string) {
+	name := header(LOG_LEVEL_HEADER)
+	if name == "" {
+		return
+	}
+	token := os.Getenv(DEBUG_TOKEN_ENV)
+	if token == "" || subtle.ConstantTimeCompare([]byte(header(DEBUG_TOKEN_HEADER)), []byte(token)) != 1 {
+		return
+	}
+	if minLvl, ok := logger.ParseLogLevel(name); ok {
+		lgr.MinLevel = minLvl
+	}
+}
+
+// startInvocationSpan will start the server span of an invocation as a child of the trace context of the
+// request headers, or of the sampled X-Ray trace of the lambda invocation without one. The request-scoped
+// logger is linked to the span.
+func startInvocationSpan(ctx context.Context, lgr *logger.Logger,
+	header func(key string) string) (context.Context, trace.Span) {
+	ctx = tracing.Extract(ctx, header)
+	if !trace.SpanContextFromContext(ctx).IsValid() {
+		ctx = tracing.ExtractLambdaEnv(ctx)
+	}
+	ctx, span := tracing.Start(ctx, INVOCATION_SPAN, trace.WithSpanKind(trace.SpanKindServer))
+	linkLoggerToSpan(lgr, span)
+	return ctx, span
+}
+
+// endInvocationSpan will end the span of an invocation and export the spans of the invocation, so they are
+// not lost when lambda freezes the process. The logs of the invocation are already flushed, so a failed
+// export is reported on stderr.
+func endInvocationSpan(ctx context.Context, span trace.Span) {
+	span.End()
+	if err := tracing.Flush(ctx); err != nil {
+		fmt.Fprintln(os.Stderr, "tracing: span flush failed.", err)
+	}
+}
+
+// linkLoggerToSpan will attach the trace and span ids of the span to the logs of lgr
+func linkLoggerToSpan(lgr *logger.Logger, span trace.Span) {
+	sc := span.SpanContext()
+	if !sc.IsValid() {
+		return
+	}
+	lgr.TraceID = sc.TraceID().String()
+	lgr.SpanID = sc.SpanID().String()
+}
+
+// recordException will add the exception of a recovered panic payload to the span. Only internal
+// server errors set the error status of the span, client errors are expected outcomes.
+func recordException(span trace.Span, recoverPayload interface{}, statusCode int) {
+	msg := fmt.Sprintf("%v", recoverPayload)
+	switch payload := recoverPayload.(type) {
+	case HTTPException:
+		msg = payload.ErrorMessage
+	case error:
+		msg = payload.Error()
+	}
+	span.AddEvent("exception", trace.WithAttributes(
+		attribute.String("exception.type", fmt.Sprintf("%T", recoverPayload)),
+		attribute.String("exception.message", msg),
+	))
+	if statusCode >= int(INTERNAL_SERVER_ERROR) {
+		span.SetStatus(codes.Error, msg)
+	}
+}
+
+// redirectStdLog will redirect the standard log package to the request-scoped logger for the invocation
+// when running in lambda, where the invocations of a process are serial. It returns the function restoring it.
+func redirectStdLog(lgr logger.Logger) func() {
+	if lambdacontext.FunctionName == "" {
+		return func() {}
+	}
+	return logger.RedirectStdLog(lgr, logger.INFO)
+}
+
+// redactSensitiveAttributes will mask the values of the sensitive attributes of the event specifications
+// in the logs of lgr
+func redactSensitiveAttributes(lgr logger.Logger, specs ...EventSpec) logger.Logger {
+	keys := []string{}
+	for _, es := range specs {
+		keys = append(keys, es.sensitiveKeys()...)
+	}
+	if len(keys) > 0 {
+		lgr.Redactor = lgr.Redactor.WithKeys(keys...)
+	}
+	return lgr
+}
+
+// ANY_ENDPOINT is the endpoint name of the request metrics of endpoints serving any path
+const ANY_ENDPOINT = "*"
+
+// endpointName will return the first non empty route template naming the endpoint in the request metrics.
+// Templates are used instead of request paths to keep the number of metric dimensions bounded.
+func endpointName(templates ...string) string {
+	for _, t := range templates {
+		if t != "" {
+			return t
+		}
+	}
+	return ANY_ENDPOINT
+}
+
+// coldStart is set until the first invocation of the process records its request metrics
+var coldStart int32 = 1
+
+// isColdStart will report if this is the first invocation of the process
+func isColdStart() bool {
+	return atomic.SwapInt32(&coldStart, 0) == 1
+}
+
+// executeServiceFunction will run the service function on the service handler of the provider.
+// It creates the service event, executes the service function and builds the response. Exceptions
+// raised anywhere in between are converted to the response by the service handler. The metrics
+// recorded through the context of the service function are flushed at the end of the invocation,
+// and the built-in request metrics of the endpoint are recorded by the recorder of the context.
+func executeServiceFunction(ctx context.Context, svh ServiceHandler, lgr logger.Logger, endpoint string,
+	es EventSpec, sf ServiceFunction, retHeaders map[string]string, options interface{}) (response interface{}) {
+	mtr := metrics.NewInvocationMetrics(lgr)
+	rm := metrics.RequestMetrics{
+		Endpoint:      endpoint,
+		RequestID:     lgr.RequestID,
+		CorrelationID: lgr.CorrelationID,
+		ColdStart:     isColdStart(),
+		StatusCode:    200,
+	}
+	phaseStart := time.Now()
+	phaseDuration := &rm.ValidationDuration
+
+	// Handle Http Exceptions
+	defer func() {
+		err := recover()
+		if err != nil {
+			// The failed phase is timed up to the exception
+			*phaseDuration = time.Since(phaseStart)
+			rm.StatusCode = int(INTERNAL_SERVER_ERROR)
+			if ex, ok := err.(HTTPException); ok {
+				rm.StatusCode = ex.StatusCode
+				rm.ValidationFailure = ex.ParameterLocation
+			}
+			recordException(trace.SpanFromContext(ctx), err, rm.StatusCode)
+			response = svh.HandleExceptions(
+				err,
+				retHeaders,
+			)
+		}
+		if recordErr := metrics.RecorderFromContext(ctx).RecordRequest(rm); recordErr != nil {
+			lgr.LogErr(logger.ERROR, "Recording request metrics failed", recordErr)
+		}
+		if flushErr := mtr.Flush(); flushErr != nil {
+			lgr.LogErr(logger.ERROR, "Flushing metrics failed", flushErr)
+		}
+		lgr.Flush(err != nil)
+	}()
+
+	se := svh.NewServiceEvent(es, options)
+	rm.ValidationDuration = time.Since(phaseStart)
+
+	// Execute the service function
+	lgr.LogTxt(logger.INFO, "Executing Service Function..")
+	phaseStart, phaseDuration = time.Now(), &rm.FunctionDuration
+	fnCtx, fnSpan := tracing.Start(ctx, SERVICE_FUNCTION_SPAN)
+	fnLgr := lgr
+	linkLoggerToSpan(&fnLgr, fnSpan)
+	responseBody := func() string {
+		completed := false
+		defer func() {
+			if completed {
+				fnSpan.End()
+				return
+			}
+			// Client errors raised by the service function are expected outcomes like in recordException
+			err := recover()
+			if ex, ok := err.(HTTPException); !ok || ex.StatusCode >= int(INTERNAL_SERVER_ERROR) {
+				fnSpan.SetStatus(codes.Error, "service function raised an exception")
+			}
+			fnSpan.End()
+			if err != nil {
+				panic(err)
+			}
+		}()
+		body := sf(metrics.IntoContext(logger.IntoContext(fnCtx, fnLgr), mtr), se, fnLgr)
+		completed = true
+		return body
+	}()
+	rm.FunctionDuration = time.Since(phaseStart)
+
+	// Generate New HTTP Response
+	lgr.LogTxt(logger.INFO, "Building Response..")
+	phaseStart, phaseDuration = time.Now(), &rm.ResponseDuration
+	response = svh.NewHTTPResponse(ServiceResponse{
+		StatusCode:    rm.StatusCode,
+		ReturnBody:    responseBody,
+		ReturnHeaders: retHeaders,
+	})
+	rm.ResponseDuration = time.Since(phaseStart)
+	return response
+}
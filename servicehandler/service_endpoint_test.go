@@ -0,0 +1,76 @@
+package servicehandler
+
+import (
+	"go-micro/logger"
+	"os"
+	"testing"
+)
+
+var overrideLogLevelTests = []struct {
+	testName  string
+	headers   map[string]string
+	wantLevel logger.LogLevel
+}{
+	{"trusted override", map[string]string{LOG_LEVEL_HEADER: "DEBUG", DEBUG_TOKEN_HEADER: "secret"}, logger.DEBUG},
+	{"case insensitive headers", map[string]string{"x-log-level": "trace", "x-debug-token": "secret"}, logger.TRACE},
+	{"missing token", map[string]string{LOG_LEVEL_HEADER: "DEBUG"}, logger.WARN},
+	{"invalid token", map[string]string{LOG_LEVEL_HEADER: "DEBUG", DEBUG_TOKEN_HEADER: "guess"}, logger.WARN},
+	{"invalid level", map[string]string{LOG_LEVEL_HEADER: "verbose", DEBUG_TOKEN_HEADER: "secret"}, logger.WARN},
+	{"no override", map[string]string{}, logger.WARN},
+}
+
+func TestOverrideLogLevel(t *testing.T) {
+	os.Setenv(DEBUG_TOKEN_ENV, "secret")
+	defer os.Unsetenv(DEBUG_TOKEN_ENV)
+
+	for _, tt := range overrideLogLevelTests {
+		t.Run(tt.testName, func(t *testing.T) {
+			lgr := logger.NewLogger()
+			lgr.MinLevel = logger.WARN
+			overrideLogLevel(&lgr, func(key string) string {
+				return headerValue(tt.headers, key)
+			})
+			if lgr.MinLevel != tt.wantLevel {
+				t.Errorf("log level override got %v, want %v", lgr.MinLevel, tt.wantLevel)
+			}
+		})
+	}
+}
+
+func TestOverrideLogLevelWithoutToken(t *testing.T) {
+	os.Unsetenv(DEBUG_TOKEN_ENV)
+	lgr := logger.NewLogger()
+	lgr.MinLevel = logger.WARN
+	overrideLogLevel(&lgr, func(key string) string {
+		return map[string]string{LOG_LEVEL_HEADER: "DEBUG", DEBUG_TOKEN_HEADER: ""}[key]
+	})
+	if lgr.MinLevel != logger.WARN {
+		t.Errorf("log level override honored without a configured debug token")
+	}
+}
+
+var correlationIDFromHeadersTests = []struct {
+	testName          string
+	headers           map[string]string
+	wantCorrelationID string
+}{
+	{"canonical header", map[string]string{CORRELATION_ID_HEADER: "correlation-1"}, "correlation-1"},
+	{"lower case header", map[string]string{"x-correlation-id": "correlation-2"}, "correlation-2"},
+	{"upper case header", map[string]string{"X-CORRELATION-ID": "correlation-3"}, "correlation-3"},
+	{"missing header", map[string]string{}, ""},
+	{"invalid header", map[string]string{"x-correlation-id": "correlation 4"}, ""},
+}
+
+func TestCorrelationIDFromHeaders(t *testing.T) {
+	for _, tt := range correlationIDFromHeadersTests {
+		t.Run(tt.testName, func(t *testing.T) {
+			got := CorrelationIDFromHeaders(tt.headers)
+			if tt.wantCorrelationID != "" && got != tt.wantCorrelationID {
+				t.Errorf("correlation id got %v, want %v", got, tt.wantCorrelationID)
+			}
+			if tt.wantCorrelationID == "" && !generatedIDPattern.MatchString(got) {
+				t.Errorf("correlation id got %v, want a generated id", got)
+			}
+		})
+	}
+}
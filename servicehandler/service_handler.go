@@ -17,17 +17,23 @@ const (
 
 /* Param Type for Parse Code */
 const (
-	REQ_BODY     = iota
-	QUERY_PARAMS = iota
-	PATH_PARAMS  = iota
+	REQ_BODY      = iota
+	QUERY_PARAMS  = iota
+	PATH_PARAMS   = iota
+	EVENT_DETAIL  = iota
+	STREAM_RECORD = iota
+	MESSAGE_BODY  = iota
+	MESSAGE_ATTRS = iota
 )
 
-/* Required Event Specification Attribute */
+// ReqEventAttrib is the Required Event Specification Attribute.
+// The values of the attributes with IsSensitive are masked in the logs.
 type ReqEventAttrib struct {
-	DataType   string
-	IsRequired bool
-	MinLength  int
-	MaxLength  int
+	DataType    string
+	IsRequired  bool
+	MinLength   int
+	MaxLength   int
+	IsSensitive bool
 }
 
 /* Service Event specification */
@@ -35,6 +41,8 @@ type EventSpec struct {
 	RequiredRequestBody ReqEventSpec
 	RequiredQueryParams ReqEventSpec
 	RequiredPathParams  ReqEventSpec
+	// RequiredMessageAttributes is only checked by the SNS endpoint
+	RequiredMessageAttributes ReqEventSpec
 }
 
 /* Required Event specification */
@@ -44,11 +52,12 @@ type ReqEventSpec struct {
 
 /* Request on Service Event */
 type ServiceEvent struct {
-	Identity    events.APIGatewayRequestIdentity
-	RequestBody map[string]interface{}
-	QueryParams map[string]interface{}
-	PathParams  map[string]interface{}
-	Options     interface{}
+	Identity      events.APIGatewayRequestIdentity
+	CorrelationID string
+	RequestBody   map[string]interface{}
+	QueryParams   map[string]interface{}
+	PathParams    map[string]interface{}
+	Options       interface{}
 }
 
 type ServiceResponse struct {
@@ -64,9 +73,13 @@ type ServiceHandler interface {
 }
 
 var parameterMap = map[int]string{
-	QUERY_PARAMS: "Query Parameter",
-	PATH_PARAMS:  "Path Parameter",
-	REQ_BODY:     "Request Body",
+	QUERY_PARAMS:  "Query Parameter",
+	PATH_PARAMS:   "Path Parameter",
+	REQ_BODY:      "Request Body",
+	EVENT_DETAIL:  "Event Detail",
+	STREAM_RECORD: "Stream Record",
+	MESSAGE_BODY:  "Message Body",
+	MESSAGE_ATTRS: "Message Attributes",
 }
 
 var errMsgMap = map[int]string{
@@ -75,12 +88,13 @@ var errMsgMap = map[int]string{
 	INVALID_ATTRIBUTE_TYPE_ERROR:   "INVALID ATTRIBUTE TYPE",
 }
 
-// causePanic will raise an http exception via that'll cause a panic and should be recovered
+// causePanic will raise a bad request http exception of the parameter that'll cause a panic and should be recovered
 func causePanic(paramType int, parseCode int, errorMsg string) {
-	RaiseHTTPException(
-		BAD_REQUEST,
-		fmt.Sprintf("Error in %v, %v. %v", parameterMap[paramType], errMsgMap[parseCode], errorMsg),
-	)
+	panic(HTTPException{
+		StatusCode:        int(BAD_REQUEST),
+		ErrorMessage:      fmt.Sprintf("Error in %v, %v. %v", parameterMap[paramType], errMsgMap[parseCode], errorMsg),
+		ParameterLocation: parameterMap[paramType],
+	})
 }
 
 // NewReqEventAttrib will create a new ReqEventAttrib object
@@ -105,6 +119,36 @@ func NewReqEvenAttrib(dataType string, isRequired bool, minLength int, maxLength
 
 }
 
+// Sensitive will return a copy of the attribute marked as sensitive
+func (rqa ReqEventAttrib) Sensitive() ReqEventAttrib {
+	rqa.IsSensitive = true
+	return rqa
+}
+
+// sensitiveKeys will return the names of the sensitive attributes of the specification at any depth
+func (res ReqEventSpec) sensitiveKeys() []string {
+	keys := []string{}
+	for k, v := range res.ReqEventAttributes {
+		switch attrib := v.(type) {
+		case ReqEventAttrib:
+			if attrib.IsSensitive {
+				keys = append(keys, k)
+			}
+		case map[string]interface{}:
+			keys = append(keys, ReqEventSpec{ReqEventAttributes: attrib}.sensitiveKeys()...)
+		}
+	}
+	return keys
+}
+
+// sensitiveKeys will return the names of the sensitive attributes of the event specification
+func (es EventSpec) sensitiveKeys() []string {
+	keys := es.RequiredRequestBody.sensitiveKeys()
+	keys = append(keys, es.RequiredQueryParams.sensitiveKeys()...)
+	keys = append(keys, es.RequiredPathParams.sensitiveKeys()...)
+	return append(keys, es.RequiredMessageAttributes.sensitiveKeys()...)
+}
+
 // isInRange will check if in is between the given range
 func isInRange(in interface{}, min int, max int) bool {
 	vType := reflect.TypeOf(in).String()
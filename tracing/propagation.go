@@ -0,0 +1,76 @@
+package tracing
+
+import (
+	"context"
+	"os"
+
+	"go.opentelemetry.io/contrib/propagators/aws/xray"
+	"go.opentelemetry.io/otel/propagation"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// Trace context headers of the W3C Trace Context and AWS X-Ray propagation formats
+const (
+	TRACEPARENT_HEADER = "traceparent"
+	XRAY_TRACE_HEADER  = "X-Amzn-Trace-Id"
+)
+
+// XRAY_TRACE_ENV is the environment variable the lambda runtime sets to the X-Ray trace header of the invocation
+const XRAY_TRACE_ENV = "_X_AMZN_TRACE_ID"
+
+// Propagator reads and writes the W3C traceparent and X-Ray trace headers. The traceparent header takes
+// precedence when a request has both.
+var Propagator propagation.TextMapPropagator = propagation.NewCompositeTextMapPropagator(
+	xray.Propagator{},
+	propagation.TraceContext{},
+)
+
+// headerCarrier is a read only propagation carrier of the header lookup of a request
+type headerCarrier func(key string) string
+
+// Get will return the value of the header.
+func (hc headerCarrier) Get(key string) string {
+	return hc(key)
+}
+
+// Set will ignore the header, the carrier is read only.
+func (hc headerCarrier) Set(key string, value string) {}
+
+// Keys will return no keys, the propagators only get their own headers.
+func (hc headerCarrier) Keys() []string {
+	return nil
+}
+
+// Extract will return a copy of ctx carrying the span context of the trace headers of an incoming request
+// as the remote parent of the spans started from it. ctx is returned as is without a valid header.
+func Extract(ctx context.Context, header func(key string) string) context.Context {
+	if ctx == nil {
+		ctx = context.Background()
+	}
+	return Propagator.Extract(ctx, headerCarrier(header))
+}
+
+// ExtractLambdaEnv will return a copy of ctx carrying the span context of the X-Ray trace header that lambda
+// sets in XRAY_TRACE_ENV. Lambda sets Sampled=0 whenever X-Ray active tracing is off, which is not a sampling
+// decision of the caller, so an unsampled header is ignored and the invocation starts a new trace sampled by
+// the root sampler of the tracer provider.
+func ExtractLambdaEnv(ctx context.Context) context.Context {
+	if ctx == nil {
+		ctx = context.Background()
+	}
+	envHeader := os.Getenv(XRAY_TRACE_ENV)
+	envCtx := xray.Propagator{}.Extract(ctx, headerCarrier(func(key string) string { return envHeader }))
+	if !trace.SpanContextFromContext(envCtx).IsSampled() {
+		return ctx
+	}
+	return envCtx
+}
+
+// Inject will set the traceparent and X-Ray trace headers of the span context of ctx, so downstream
+// calls join the trace. Nothing is set without a valid span context.
+func Inject(ctx context.Context, headers map[string]string) {
+	if !trace.SpanContextFromContext(ctx).IsValid() {
+		return
+	}
+	Propagator.Inject(ctx, propagation.MapCarrier(headers))
+}
@@ -0,0 +1,120 @@
+package tracing
+
+import (
+	"context"
+	"os"
+	"testing"
+
+	"go.opentelemetry.io/otel/trace"
+)
+
+var extractTests = []struct {
+	testName    string
+	headers     map[string]string
+	wantTraceID string
+	wantSpanID  string
+	wantSampled bool
+}{
+	{
+		"traceparent", map[string]string{TRACEPARENT_HEADER: "00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01"},
+		"4bf92f3577b34da6a3ce929d0e0e4736", "00f067aa0ba902b7", true,
+	},
+	{
+		"unsampled traceparent", map[string]string{TRACEPARENT_HEADER: "00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-00"},
+		"4bf92f3577b34da6a3ce929d0e0e4736", "00f067aa0ba902b7", false,
+	},
+	{
+		"x-ray", map[string]string{XRAY_TRACE_HEADER: "Root=1-5759e988-bd862e3fe1be46a994272793;Parent=53995c3f42cd8ad8;Sampled=1"},
+		"5759e988bd862e3fe1be46a994272793", "53995c3f42cd8ad8", true,
+	},
+	{
+		"traceparent over x-ray", map[string]string{
+			TRACEPARENT_HEADER: "00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01",
+			XRAY_TRACE_HEADER:  "Root=1-5759e988-bd862e3fe1be46a994272793;Parent=53995c3f42cd8ad8;Sampled=1",
+		},
+		"4bf92f3577b34da6a3ce929d0e0e4736", "00f067aa0ba902b7", true,
+	},
+	{
+		"x-ray with invalid traceparent", map[string]string{
+			TRACEPARENT_HEADER: "ff-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01",
+			XRAY_TRACE_HEADER:  "Root=1-5759e988-bd862e3fe1be46a994272793;Parent=53995c3f42cd8ad8;Sampled=1",
+		},
+		"5759e988bd862e3fe1be46a994272793", "53995c3f42cd8ad8", true,
+	},
+	{"invalid x-ray", map[string]string{XRAY_TRACE_HEADER: "Root=2-5759e988-bd862e3f;Parent=53995c3f42cd8ad8"}, "", "", false},
+	{"no headers", map[string]string{}, "", "", false},
+}
+
+func TestExtract(t *testing.T) {
+	for _, tt := range extractTests {
+		t.Run(tt.testName, func(t *testing.T) {
+			sc := trace.SpanContextFromContext(Extract(context.Background(), func(key string) string {
+				return tt.headers[key]
+			}))
+			if tt.wantTraceID == "" {
+				if sc.IsValid() {
+					t.Errorf("span context extracted got %+v, want none", sc)
+				}
+				return
+			}
+			if sc.TraceID().String() != tt.wantTraceID || sc.SpanID().String() != tt.wantSpanID ||
+				sc.IsSampled() != tt.wantSampled || !sc.IsRemote() {
+				t.Errorf("span context extracted got %v %v %v", sc.TraceID(), sc.SpanID(), sc.IsSampled())
+			}
+		})
+	}
+}
+
+var extractLambdaEnvTests = []struct {
+	testName    string
+	env         string
+	wantTraceID string
+}{
+	{"sampled", "Root=1-5759e988-bd862e3fe1be46a994272793;Parent=53995c3f42cd8ad8;Sampled=1",
+		"5759e988bd862e3fe1be46a994272793"},
+	{"active tracing off", "Root=1-5759e988-bd862e3fe1be46a994272793;Parent=53995c3f42cd8ad8;Sampled=0;Lineage=a87bd80c:0", ""},
+	{"unset", "", ""},
+}
+
+func TestExtractLambdaEnv(t *testing.T) {
+	env := os.Getenv(XRAY_TRACE_ENV)
+	defer os.Setenv(XRAY_TRACE_ENV, env)
+	for _, tt := range extractLambdaEnvTests {
+		t.Run(tt.testName, func(t *testing.T) {
+			os.Setenv(XRAY_TRACE_ENV, tt.env)
+			sc := trace.SpanContextFromContext(ExtractLambdaEnv(context.Background()))
+			if tt.wantTraceID == "" {
+				if sc.IsValid() {
+					t.Errorf("span context extracted got %+v, want none", sc)
+				}
+				return
+			}
+			if sc.TraceID().String() != tt.wantTraceID || !sc.IsSampled() {
+				t.Errorf("span context extracted got %v %v", sc.TraceID(), sc.IsSampled())
+			}
+		})
+	}
+}
+
+func TestInject(t *testing.T) {
+	headers := map[string]string{}
+	Inject(context.Background(), headers)
+	if len(headers) != 0 {
+		t.Errorf("headers injected without a span, got %v", headers)
+	}
+
+	tp, _ := newTestProvider(nil)
+	ctx, span := Start(TracerProviderIntoContext(context.Background(), tp), "downstream call")
+	Inject(ctx, headers)
+	for _, key := range []string{TRACEPARENT_HEADER, XRAY_TRACE_HEADER} {
+		sc := trace.SpanContextFromContext(Extract(context.Background(), func(k string) string {
+			if k == key {
+				return headers[k]
+			}
+			return ""
+		}))
+		if sc.TraceID() != span.SpanContext().TraceID() || sc.SpanID() != span.SpanContext().SpanID() {
+			t.Errorf("%v header injected got %v", key, headers[key])
+		}
+	}
+}
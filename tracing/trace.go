@@ -0,0 +1,89 @@
+package tracing
+
+import (
+	"context"
+
+	"go.opentelemetry.io/contrib/propagators/aws/xray"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// TRACER_NAME is the instrumentation name of the tracers of the service endpoints
+const TRACER_NAME = "go-micro"
+
+// NewSampler will create a parent-based sampler. Spans with a parent follow the sampling decision of
+// the parent, local or from the trace headers of the request, and root spans are sampled by root,
+// e.g. sdktrace.TraceIDRatioBased(0.1). A nil root samples every trace.
+func NewSampler(root sdktrace.Sampler) sdktrace.Sampler {
+	if root == nil {
+		root = sdktrace.AlwaysSample()
+	}
+	return sdktrace.ParentBased(root)
+}
+
+// NewTracerProvider will create an OpenTelemetry SDK tracer provider sampling with the sampler, NewSampler(nil)
+// when nil, and exporting the sampled spans to each exporter in batches, e.g. an OTLP, Jaeger or stdout
+// exporter. Trace ids are X-Ray compatible. The service endpoints flush the spans at the end of each
+// invocation before lambda freezes the process.
+func NewTracerProvider(sampler sdktrace.Sampler, exporters ...sdktrace.SpanExporter) *sdktrace.TracerProvider {
+	if sampler == nil {
+		sampler = NewSampler(nil)
+	}
+	opts := []sdktrace.TracerProviderOption{
+		sdktrace.WithSampler(sampler),
+		sdktrace.WithIDGenerator(xray.NewIDGenerator()),
+	}
+	for _, exporter := range exporters {
+		opts = append(opts, sdktrace.WithBatcher(exporter))
+	}
+	return sdktrace.NewTracerProvider(opts...)
+}
+
+// DefaultTracerProvider is the tracer provider of the spans started without a tracer provider in their
+// context. It has no exporter, so the spans only link the logs to their trace and are dropped. Replace it
+// with a provider exporting the spans, or with otel.GetTracerProvider() to use the global provider.
+var DefaultTracerProvider trace.TracerProvider = NewTracerProvider(nil)
+
+// Start will start a span with the tracer provider of the local parent span in ctx, the tracer provider
+// carried by ctx or the DefaultTracerProvider, in that order. It returns a copy of ctx carrying the span.
+func Start(ctx context.Context, name string, opts ...trace.SpanStartOption) (context.Context, trace.Span) {
+	if ctx == nil {
+		ctx = context.Background()
+	}
+	tp := TracerProviderFromContext(ctx)
+	if parent := trace.SpanFromContext(ctx); parent.SpanContext().IsValid() && !parent.SpanContext().IsRemote() {
+		tp = parent.TracerProvider()
+	}
+	return tp.Tracer(TRACER_NAME).Start(ctx, name, opts...)
+}
+
+// Flush will export the spans ended but not yet exported by the tracer provider of ctx. Providers without
+// a ForceFlush method are ignored.
+func Flush(ctx context.Context) error {
+	if tp, ok := TracerProviderFromContext(ctx).(interface{ ForceFlush(context.Context) error }); ok {
+		return tp.ForceFlush(ctx)
+	}
+	return nil
+}
+
+// tracerProviderContextKey is the key of the tracer provider in a context.Context
+type tracerProviderContextKey struct{}
+
+// TracerProviderIntoContext will return a copy of ctx carrying the tracer provider.
+func TracerProviderIntoContext(ctx context.Context, tp trace.TracerProvider) context.Context {
+	if ctx == nil {
+		ctx = context.Background()
+	}
+	return context.WithValue(ctx, tracerProviderContextKey{}, tp)
+}
+
+// TracerProviderFromContext will return the tracer provider carried by ctx, or the DefaultTracerProvider
+// without one.
+func TracerProviderFromContext(ctx context.Context) trace.TracerProvider {
+	if ctx != nil {
+		if tp, ok := ctx.Value(tracerProviderContextKey{}).(trace.TracerProvider); ok && tp != nil {
+			return tp
+		}
+	}
+	return DefaultTracerProvider
+}
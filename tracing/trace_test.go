@@ -0,0 +1,145 @@
+package tracing
+
+import (
+	"context"
+	"fmt"
+	"testing"
+	"time"
+
+	"go.opentelemetry.io/otel/attribute"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// newTestProvider will create a tracer provider exporting the spans synchronously to an in-memory exporter
+func newTestProvider(sampler sdktrace.Sampler) (*sdktrace.TracerProvider, *tracetest.InMemoryExporter) {
+	exporter := tracetest.NewInMemoryExporter()
+	if sampler == nil {
+		sampler = NewSampler(nil)
+	}
+	return sdktrace.NewTracerProvider(sdktrace.WithSampler(sampler), sdktrace.WithSyncer(exporter)), exporter
+}
+
+// remoteParent will return the span context of a traceparent header
+func remoteParent(sampled bool) trace.SpanContext {
+	flags := trace.TraceFlags(0)
+	if sampled {
+		flags = trace.FlagsSampled
+	}
+	return trace.NewSpanContext(trace.SpanContextConfig{
+		TraceID:    trace.TraceID{0x4b, 0xf9, 0x2f, 0x35, 0x77, 0xb3, 0x4d, 0xa6, 0xa3, 0xce, 0x92, 0x9d, 0x0e, 0x0e, 0x47, 0x36},
+		SpanID:     trace.SpanID{0x00, 0xf0, 0x67, 0xaa, 0x0b, 0xa9, 0x02, 0xb7},
+		TraceFlags: flags,
+		Remote:     true,
+	})
+}
+
+func TestStart(t *testing.T) {
+	tp, exporter := newTestProvider(nil)
+	ctx := TracerProviderIntoContext(context.Background(), tp)
+
+	ctx, root := Start(ctx, "invocation")
+	// The child uses the provider of its parent span over the default provider of its context
+	childCtx, child := Start(TracerProviderIntoContext(ctx, DefaultTracerProvider), "service function")
+	child.SetAttributes(attribute.String("userId", "1234"))
+	child.End()
+	root.End()
+
+	spans := exporter.GetSpans()
+	if len(spans) != 2 || spans[0].Name != "service function" || spans[1].Name != "invocation" {
+		t.Fatalf("exported spans got %v", spans)
+	}
+	rootStub, childStub := spans[1], spans[0]
+	if !rootStub.SpanContext.IsSampled() || rootStub.Parent.IsValid() {
+		t.Errorf("root span context got %+v, parent %+v", rootStub.SpanContext, rootStub.Parent)
+	}
+	if childStub.SpanContext.TraceID() != rootStub.SpanContext.TraceID() ||
+		childStub.Parent.SpanID() != rootStub.SpanContext.SpanID() {
+		t.Errorf("child span not linked to its parent, got %+v", childStub)
+	}
+	if len(childStub.Attributes) != 1 || childStub.Attributes[0].Value.AsString() != "1234" {
+		t.Errorf("span attributes got %v", childStub.Attributes)
+	}
+	if trace.SpanFromContext(childCtx) != child {
+		t.Errorf("span not carried by the context")
+	}
+}
+
+var remoteParentTests = []struct {
+	testName    string
+	sampled     bool
+	root        sdktrace.Sampler
+	wantExports int
+}{
+	{"sampled remote parent", true, nil, 1},
+	{"unsampled remote parent", false, nil, 0},
+	{"sampled remote parent with never sampled roots", true, sdktrace.NeverSample(), 1},
+}
+
+func TestStartRemoteParent(t *testing.T) {
+	for _, tt := range remoteParentTests {
+		t.Run(tt.testName, func(t *testing.T) {
+			tp, exporter := newTestProvider(NewSampler(tt.root))
+			remote := remoteParent(tt.sampled)
+			ctx := TracerProviderIntoContext(trace.ContextWithRemoteSpanContext(context.Background(), remote), tp)
+
+			_, span := Start(ctx, "invocation")
+			span.End()
+			if got := len(exporter.GetSpans()); got != tt.wantExports {
+				t.Fatalf("exported spans got %v, want %v", got, tt.wantExports)
+			}
+			sc := span.SpanContext()
+			if sc.TraceID() != remote.TraceID() || sc.SpanID() == remote.SpanID() || sc.IsSampled() != tt.sampled {
+				t.Errorf("span does not continue the remote trace, got %+v", sc)
+			}
+		})
+	}
+}
+
+func TestNewSamplerRoot(t *testing.T) {
+	tp, exporter := newTestProvider(NewSampler(sdktrace.NeverSample()))
+	_, span := Start(TracerProviderIntoContext(context.Background(), tp), "invocation")
+	span.End()
+	if len(exporter.GetSpans()) != 0 || span.SpanContext().IsSampled() {
+		t.Errorf("root span sampled by a never sampling root sampler")
+	}
+}
+
+func TestNewTracerProvider(t *testing.T) {
+	exporter := tracetest.NewInMemoryExporter()
+	tp := NewTracerProvider(nil, exporter)
+	defer tp.Shutdown(context.Background())
+	ctx := TracerProviderIntoContext(context.Background(), tp)
+
+	_, span := Start(ctx, "invocation")
+	span.End()
+	if err := Flush(ctx); err != nil {
+		t.Fatalf("flush failed: %v", err)
+	}
+	spans := exporter.GetSpans()
+	if len(spans) != 1 {
+		t.Fatalf("exported spans after flush got %v, want 1", len(spans))
+	}
+	// X-Ray trace ids start with the unix time of the trace
+	tid := spans[0].SpanContext.TraceID().String()
+	if epoch := fmt.Sprintf("%08x", time.Now().Unix()); tid[:6] != epoch[:6] {
+		t.Errorf("trace id %v does not start with the epoch %v", tid, epoch)
+	}
+}
+
+func TestTracerProviderContext(t *testing.T) {
+	tp, _ := newTestProvider(nil)
+	if TracerProviderFromContext(TracerProviderIntoContext(context.Background(), tp)) != tp {
+		t.Errorf("tracer provider not carried by the context")
+	}
+	if TracerProviderFromContext(context.Background()) != DefaultTracerProvider {
+		t.Errorf("context without tracer provider does not fall back to the default provider")
+	}
+	if err := Flush(context.Background()); err != nil {
+		t.Errorf("flush of the default provider failed: %v", err)
+	}
+	if _, span := Start(nil, "invocation"); !span.SpanContext().IsValid() {
+		t.Errorf("span of the default provider has no ids to link the logs to")
+	}
+}